@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // AlertSpec defines the desired state of Alert
@@ -27,6 +28,56 @@ type AlertSpec struct {
 
 }
 
+// InvolvedObject references the Kubernetes object an alert is about, as identified by one of its labels.
+type InvolvedObject struct {
+	// Label is the alert label which contains the name of the object.
+	Label string `json:"label"`
+	// APIVersion of the object, e.g. "apps/v1".
+	APIVersion string `json:"apiVersion"`
+	// Kind of the object, e.g. "Deployment".
+	Kind string `json:"kind"`
+	// Namespace of the object, empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the object.
+	Name string `json:"name"`
+	// UID of the object, if it exists.
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
+	// Exists indicates whether the object was found when the alert was last seen.
+	Exists bool `json:"exists"`
+}
+
+// AlertRule describes the Prometheus alerting rule which produced an alert.
+type AlertRule struct {
+	// Name of the alerting rule.
+	Name string `json:"name"`
+	// Group is the name of the rule group the rule belongs to.
+	Group string `json:"group"`
+	// File is the rule file which contains the rule group.
+	// +optional
+	File string `json:"file,omitempty"`
+	// Query is the PromQL expression of the rule.
+	Query string `json:"query"`
+	// For is the duration for which the expression needs to be true before the alert fires.
+	// +optional
+	For *metav1.Duration `json:"for,omitempty"`
+	// Health of the last evaluation of the rule, one of "ok", "err" or "unknown".
+	// +optional
+	Health string `json:"health,omitempty"`
+	// LastError is the error of the last evaluation of the rule, if it failed.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// LastEvaluation describes when the rule was last evaluated.
+	// +optional
+	LastEvaluation *metav1.Time `json:"lastEvaluation,omitempty"`
+}
+
+const (
+	// AlertConditionRuleHealthy indicates whether the last evaluation of the alerting rule succeeded.
+	AlertConditionRuleHealthy = "RuleHealthy"
+)
+
 // AlertStatus defines the observed state of Alert
 type AlertStatus struct {
 	// State describes if the alert is currently active or not.
@@ -35,10 +86,69 @@ type AlertStatus struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// Labels contains key-value data associated to the alert.
 	Labels map[string]string `json:"labels,omitempty"`
-	// Describes since which timestamp the alert is active.
-	Since string `json:"since,omitempty"` // TODO: use a proper timestamp
+	// ActiveAt describes since which timestamp the alert is active.
+	// +optional
+	ActiveAt *metav1.Time `json:"activeAt,omitempty"`
+	// LastSeen describes when the alert was last reported as active.
+	// +optional
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+	// Since is the string representation of ActiveAt written by previous versions of the operator.
+	// Deprecated: use ActiveAt instead. The field is migrated and cleared by the controller.
+	// +optional
+	Since string `json:"since,omitempty"`
 	// The current value of alert expression.
-	Value string	`json:"value,omitempty"`
+	Value string `json:"value,omitempty"`
+	// ResolvedAt describes since which timestamp the alert is no longer active.
+	// +optional
+	ResolvedAt *metav1.Time `json:"resolvedAt,omitempty"`
+
+	// Fingerprint is the unique identifier Alertmanager computes for the alert's label set.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// InvolvedObjects references the Kubernetes objects named by the labels of the alert (e.g. its pod or node).
+	// +optional
+	InvolvedObjects []InvolvedObject `json:"involvedObjects,omitempty"`
+	// Sources contains the names of the alert sources which currently report the alert. The other fields of the status
+	// are taken from the first of them.
+	// +optional
+	Sources []string `json:"sources,omitempty"`
+	// Replicas identifies the HA replicas which reported the alert, by the values of their replica labels
+	// (e.g. "prometheus_replica=prometheus-k8s-0"). The replica labels are removed from the labels of the alert.
+	// +optional
+	Replicas []string `json:"replicas,omitempty"`
+	// Rule describes the alerting rule which produced the alert. It is only populated for alerts loaded from Prometheus.
+	// +optional
+	Rule *AlertRule `json:"rule,omitempty"`
+	// Conditions represent the latest observations of the alert's state.
+	// Known condition types are "RuleHealthy".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// The following fields are only populated when alerts are loaded from Alertmanager.
+
+	// Receivers contains the names of the receivers the alert is routed to.
+	// +optional
+	Receivers []string `json:"receivers,omitempty"`
+	// GeneratorURL links back to the entity that generated the alert (e.g. the Prometheus expression browser).
+	// +optional
+	GeneratorURL string `json:"generatorURL,omitempty"`
+	// StartsAt describes since which timestamp Alertmanager considers the alert active.
+	// +optional
+	StartsAt *metav1.Time `json:"startsAt,omitempty"`
+	// EndsAt describes at which timestamp Alertmanager considers the alert resolved unless it is refreshed.
+	// +optional
+	EndsAt *metav1.Time `json:"endsAt,omitempty"`
+	// UpdatedAt describes when the alert was last updated in Alertmanager.
+	// +optional
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+	// SilencedBy contains the IDs of the silences that currently mute the alert.
+	// +optional
+	SilencedBy []string `json:"silencedBy,omitempty"`
+	// InhibitedBy contains the fingerprints of the alerts that currently inhibit the alert.
+	// +optional
+	InhibitedBy []string `json:"inhibitedBy,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -46,7 +156,11 @@ type AlertStatus struct {
 
 // Alert is the Schema for the alerts API
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
-// +kubebuilder:printcolumn:name="Since",type=string,JSONPath=`.status.since`
+// +kubebuilder:printcolumn:name="Value",type=string,JSONPath=`.status.value`
+// +kubebuilder:printcolumn:name="Since",type=date,JSONPath=`.status.activeAt`
+// +kubebuilder:printcolumn:name="Last Seen",type=date,JSONPath=`.status.lastSeen`,priority=1
+// +kubebuilder:printcolumn:name="Resolved",type=date,JSONPath=`.status.resolvedAt`
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.status.sources[0]`,priority=1
 // https://book.kubebuilder.io/reference/generating-crd.html#additional-printer-columns
 type Alert struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AlertSourceSpec defines the desired state of AlertSource
+type AlertSourceSpec struct {
+	// URL of the Prometheus-compatible API the alerts are loaded from (e.g. Prometheus or Thanos Ruler),
+	// for example "https://prometheus-k8s.monitoring.svc:9091". Alerts are fetched from its /api/v1/alerts endpoint.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// BearerTokenSecret selects the key of a Secret in the namespace of the AlertSource which contains the token
+	// that is sent as Bearer Authorization header.
+	// +optional
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+	// TLS configures how the TLS certificate presented by the endpoint is verified.
+	// +optional
+	TLS *AlertSourceTLSConfig `json:"tls,omitempty"`
+	// Interval at which alerts are loaded from the endpoint. Defaults to 15s.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Selector restricts the alerts loaded from the endpoint to those whose labels match. By default, all alerts are loaded.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// AlertSourceTLSConfig configures how the TLS certificate presented by an alert source is verified.
+type AlertSourceTLSConfig struct {
+	// InsecureSkipVerify disables the verification of the certificate.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// CASecret selects the key of a Secret in the namespace of the AlertSource which contains a PEM-encoded CA bundle
+	// that is trusted in addition to the system roots.
+	// +optional
+	CASecret *corev1.SecretKeySelector `json:"caSecret,omitempty"`
+}
+
+const (
+	// AlertSourceConditionReady indicates whether the alerts were loaded from the endpoint successfully.
+	AlertSourceConditionReady = "Ready"
+)
+
+// AlertSourceStatus defines the observed state of AlertSource
+type AlertSourceStatus struct {
+	// LastSyncTime is the time at which alerts were last loaded from the endpoint successfully.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// Alerts is the number of active alerts which were loaded from the endpoint.
+	// +optional
+	Alerts int32 `json:"alerts,omitempty"`
+	// ObservedGeneration is the generation of the spec that was last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest observations of the alert source's state.
+	// Known condition types are "Ready".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// AlertSource is the Schema for the alertsources API.
+// It describes a Prometheus-compatible endpoint which the operator loads alerts from.
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Alerts",type=integer,JSONPath=`.status.alerts`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+type AlertSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AlertSourceSpec   `json:"spec,omitempty"`
+	Status AlertSourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AlertSourceList contains a list of AlertSource
+type AlertSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AlertSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AlertSource{}, &AlertSourceList{})
+}
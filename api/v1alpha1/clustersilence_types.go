@@ -0,0 +1,54 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterSilence is the Schema for the clustersilences API.
+// Unlike a Silence in a tenant namespace, its matchers are not restricted to a namespace.
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Creator",type=string,JSONPath=`.spec.createdBy`
+// +kubebuilder:printcolumn:name="Comment",type=string,JSONPath=`.spec.comment`
+// +kubebuilder:printcolumn:name="Alerts",type=integer,JSONPath=`.status.silencedAlerts`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=1
+// +kubebuilder:printcolumn:name="Ends",type=date,JSONPath=`.status.endsAt`,priority=1
+type ClusterSilence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SilenceSpec   `json:"spec,omitempty"`
+	Status SilenceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterSilenceList contains a list of ClusterSilence
+type ClusterSilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterSilence `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterSilence{}, &ClusterSilenceList{})
+}
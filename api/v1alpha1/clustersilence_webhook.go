@@ -0,0 +1,35 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks.
+// ClusterSilences are defaulted and validated just like Silences.
+func (r *ClusterSilence) SetupWebhookWithManager(mgr ctrl.Manager, defaulter *SilenceCustomDefaulter, validator *SilenceCustomValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(defaulter).
+		WithValidator(validator).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-alertmanager-prometheus-io-alertmanager-prometheus-io-v1alpha1-clustersilence,mutating=true,failurePolicy=fail,sideEffects=None,groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=clustersilences,verbs=create;update,versions=v1alpha1,name=mclustersilence.kb.io,admissionReviewVersions=v1
+
+// +kubebuilder:webhook:path=/validate-alertmanager-prometheus-io-alertmanager-prometheus-io-v1alpha1-clustersilence,mutating=false,failurePolicy=fail,sideEffects=None,groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=clustersilences,verbs=create;update,versions=v1alpha1,name=vclustersilence.kb.io,admissionReviewVersions=v1
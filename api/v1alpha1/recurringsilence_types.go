@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecurringSilenceSpec defines the desired state of RecurringSilence
+type RecurringSilenceSpec struct {
+	// Schedule at which the windows start, in cron format (e.g. "0 22 * * 6" for every Saturday at 22:00).
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// TimeZone in which the schedule is interpreted, as a name from the IANA time zone database (e.g. "Europe/Zurich").
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Duration of each window (e.g. "4h").
+	Duration metav1.Duration `json:"duration"`
+	// CreateBefore is how long before the start of a window its silence is created in Alertmanager. Defaults to 1h.
+	// +optional
+	CreateBefore *metav1.Duration `json:"createBefore,omitempty"`
+	// Suspend stops creating silences for future windows. Silences which already exist are left until they end.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// MatchLabels contains the set of labels (non-regexed) that the silences apply to.
+	// It is a shorthand for Matchers with the "=" match type.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// Matchers contains the label matchers that the silences apply to, in addition to MatchLabels.
+	// +optional
+	Matchers []Matcher `json:"matchers,omitempty"`
+	// CreatedBy indicates the user who created the silences.
+	CreatedBy string `json:"createdBy,omitempty"`
+	// Comment contains additional information about the silences, e.g. the reason for them.
+	Comment string `json:"comment,omitempty"`
+}
+
+// SilenceWindow is a period of time during which alerts are silenced.
+type SilenceWindow struct {
+	// StartsAt is the start time of the window.
+	StartsAt metav1.Time `json:"startsAt"`
+	// EndsAt is the end time of the window.
+	EndsAt metav1.Time `json:"endsAt"`
+}
+
+// RecurringSilenceStatus defines the observed state of RecurringSilence
+type RecurringSilenceStatus struct {
+	// PreviousWindow is the most recent window which has started, it may still be ongoing.
+	// +optional
+	PreviousWindow *SilenceWindow `json:"previousWindow,omitempty"`
+	// NextWindow is the next window which has not started yet.
+	// +optional
+	NextWindow *SilenceWindow `json:"nextWindow,omitempty"`
+	// Silences lists the names of the Silence objects which currently exist for the windows.
+	// +optional
+	Silences []string `json:"silences,omitempty"`
+	// ObservedGeneration is the generation of the spec that was last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest observations of the recurring silence's state.
+	// Known condition types are "Ready".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// RecurringSilence is the Schema for the recurringsilences API.
+// It creates a Silence object for every window of its schedule.
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.spec.duration`
+// +kubebuilder:printcolumn:name="Next",type=date,JSONPath=`.status.nextWindow.startsAt`
+// +kubebuilder:printcolumn:name="Comment",type=string,JSONPath=`.spec.comment`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=1
+type RecurringSilence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RecurringSilenceSpec   `json:"spec,omitempty"`
+	Status RecurringSilenceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RecurringSilenceList contains a list of RecurringSilence
+type RecurringSilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RecurringSilence `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RecurringSilence{}, &RecurringSilenceList{})
+}
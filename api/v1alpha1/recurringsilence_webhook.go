@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks.
+// RecurringSilences are defaulted and validated like the Silences which are created for their windows, since the
+// operator creates those on behalf of the user.
+func (r *RecurringSilence) SetupWebhookWithManager(mgr ctrl.Manager, defaulter *SilenceCustomDefaulter, validator *SilenceCustomValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&RecurringSilenceCustomDefaulter{defaulter}).
+		WithValidator(&RecurringSilenceCustomValidator{validator}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-alertmanager-prometheus-io-alertmanager-prometheus-io-v1alpha1-recurringsilence,mutating=true,failurePolicy=fail,sideEffects=None,groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=recurringsilences,verbs=create;update,versions=v1alpha1,name=mrecurringsilence.kb.io,admissionReviewVersions=v1
+
+// RecurringSilenceCustomDefaulter fills in the creator of a RecurringSilence like SilenceCustomDefaulter does for
+// Silences.
+type RecurringSilenceCustomDefaulter struct {
+	*SilenceCustomDefaulter
+}
+
+var _ webhook.CustomDefaulter = &RecurringSilenceCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (d *RecurringSilenceCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	recurringSilence, ok := obj.(*RecurringSilence)
+	if !ok {
+		return fmt.Errorf("expected a RecurringSilence object but got %T", obj)
+	}
+	silencelog.V(5).Info("default", "name", recurringSilence.Name, "namespace", recurringSilence.Namespace)
+
+	if recurringSilence.DeletionTimestamp != nil {
+		return nil
+	}
+	return d.defaultCreatedBy(ctx, GroupVersion.WithResource("recurringsilences").GroupResource(),
+		recurringSilence.Name, &recurringSilence.Spec.CreatedBy)
+}
+
+// +kubebuilder:webhook:path=/validate-alertmanager-prometheus-io-alertmanager-prometheus-io-v1alpha1-recurringsilence,mutating=false,failurePolicy=fail,sideEffects=None,groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=recurringsilences,verbs=create;update,versions=v1alpha1,name=vrecurringsilence.kb.io,admissionReviewVersions=v1
+
+// RecurringSilenceCustomValidator rejects RecurringSilences whose windows would be rejected as Silences, as well as
+// invalid schedules.
+type RecurringSilenceCustomValidator struct {
+	*SilenceCustomValidator
+}
+
+var _ webhook.CustomValidator = &RecurringSilenceCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *RecurringSilenceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	recurringSilence, ok := obj.(*RecurringSilence)
+	if !ok {
+		return nil, fmt.Errorf("expected a RecurringSilence object but got %T", obj)
+	}
+	silencelog.V(5).Info("validate create", "name", recurringSilence.Name, "namespace", recurringSilence.Namespace)
+
+	return nil, v.validateRecurringSilence(recurringSilence)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *RecurringSilenceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	recurringSilence, ok := newObj.(*RecurringSilence)
+	if !ok {
+		return nil, fmt.Errorf("expected a RecurringSilence object but got %T", newObj)
+	}
+	oldRecurringSilence, ok := oldObj.(*RecurringSilence)
+	if !ok {
+		return nil, fmt.Errorf("expected a RecurringSilence object but got %T", oldObj)
+	}
+	silencelog.V(5).Info("validate update", "name", recurringSilence.Name, "namespace", recurringSilence.Namespace)
+
+	// objects which already exist must not get stuck, e.g. when they are being deleted
+	if recurringSilence.DeletionTimestamp != nil || apiequality.Semantic.DeepEqual(oldRecurringSilence.Spec, recurringSilence.Spec) {
+		return nil, nil
+	}
+
+	return nil, v.validateRecurringSilence(recurringSilence)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *RecurringSilenceCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *RecurringSilenceCustomValidator) validateRecurringSilence(recurringSilence *RecurringSilence) error {
+	spec := recurringSilence.Spec
+	specPath := field.NewPath("spec")
+	allErrs := validateSilenceMatchers(SilenceSpec{MatchLabels: spec.MatchLabels, Matchers: spec.Matchers})
+
+	if _, err := cron.ParseStandard(spec.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), spec.Schedule, err.Error()))
+	}
+	if spec.TimeZone != "" {
+		if _, err := time.LoadLocation(spec.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("timeZone"), spec.TimeZone, err.Error()))
+		}
+	}
+	switch {
+	case spec.Duration.Duration <= 0:
+		allErrs = append(allErrs, field.Invalid(specPath.Child("duration"), spec.Duration.Duration.String(), "must be positive"))
+	case v.MaxDuration > 0 && spec.Duration.Duration > v.MaxDuration:
+		allErrs = append(allErrs, field.Invalid(specPath.Child("duration"), spec.Duration.Duration.String(),
+			fmt.Sprintf("the silences must not last longer than %s", v.MaxDuration)))
+	}
+	if spec.CreateBefore != nil && spec.CreateBefore.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("createBefore"), spec.CreateBefore.Duration.String(), "must not be negative"))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("RecurringSilence").GroupKind(), recurringSilence.Name, allErrs)
+	}
+	return nil
+}
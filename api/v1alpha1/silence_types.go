@@ -20,36 +20,141 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// MatchType is the operator used for matching the value of a label.
+// +kubebuilder:validation:Enum="=";"!=";"=~";"!~"
+type MatchType string
+
+const (
+	// MatchEqual matches alerts where the label value is equal to the matcher value.
+	MatchEqual MatchType = "="
+	// MatchNotEqual matches alerts where the label value is not equal to the matcher value.
+	MatchNotEqual MatchType = "!="
+	// MatchRegexp matches alerts where the label value matches the regular expression.
+	MatchRegexp MatchType = "=~"
+	// MatchNotRegexp matches alerts where the label value does not match the regular expression.
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher describes which alerts are matched based on the value of a label.
+type Matcher struct {
+	// Name of the label to match.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Value to match the label against. For the regex match types, this is an (anchored) RE2 regular expression.
+	Value string `json:"value"`
+	// MatchType is the operator used for matching, one of "=", "!=", "=~" or "!~".
+	// +kubebuilder:default="="
+	// +optional
+	MatchType MatchType `json:"matchType,omitempty"`
+}
+
 // SilenceSpec defines the desired state of Silence
 type SilenceSpec struct {
 	// TODO: CRD validation https://book.kubebuilder.io/reference/markers/crd-validation.html
-	// TODO: MatchRegexes	
 
 	// MatchLabels contains the set of labels (non-regexed) that this silence applies to.
+	// It is a shorthand for Matchers with the "=" match type.
 	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// Matchers contains the label matchers that this silence applies to, in addition to MatchLabels.
+	// +optional
+	Matchers []Matcher `json:"matchers,omitempty"`
 	// StartsAt contains the timestamp indicating at which time the silence began.
-	StartsAt  metav1.Time `json:"startsAt,omitempty"`  // should be auto-filled
+	// Defaults to the creation time of the Silence object.
+	// +optional
+	StartsAt metav1.Time `json:"startsAt,omitempty"`
 	// EndsAt contains the timestamp indicating at which time the silence ends.
-	EndsAt    metav1.Time `json:"endsAt,omitempty"`    // provide go-duration input?
+	// Exactly one of EndsAt and Duration must be set.
+	// +optional
+	EndsAt metav1.Time `json:"endsAt,omitempty"`
+	// Duration of the silence relative to StartsAt (e.g. "4h" or "30m"), as an alternative to EndsAt.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
 	// CreatedBy indicates the user who created the silence.
-	CreatedBy string      `json:"createdBy,omitempty"` // creator
+	CreatedBy string `json:"createdBy,omitempty"` // creator
 	// Comment contains additional information about the silence, e.g. the reason for it.
-	Comment   string      `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+const (
+	// SilenceOwnerLabel indicates whether the silence is authored in Kubernetes or in Alertmanager.
+	SilenceOwnerLabel = "alertmanager.prometheus.io/owner"
+	// SilenceOwnerKubernetes marks silences whose Silence object is the source of truth, Alertmanager is updated to match it.
+	SilenceOwnerKubernetes = "kubernetes"
+	// SilenceOwnerAlertmanager marks read-only Silence objects which mirror a silence created in Alertmanager.
+	SilenceOwnerAlertmanager = "alertmanager"
+)
+
+const (
+	// SilenceConditionReady indicates whether the silence is currently active in Alertmanager.
+	SilenceConditionReady = "Ready"
+	// SilenceConditionSynced indicates whether the silence in Alertmanager reflects the spec.
+	SilenceConditionSynced = "Synced"
+	// SilenceConditionExpired indicates whether the silence has ended.
+	SilenceConditionExpired = "Expired"
+	// SilenceConditionDrifted indicates whether the silence in Alertmanager had to be restored from the spec.
+	SilenceConditionDrifted = "Drifted"
+	// SilenceConditionUnused indicates whether the active silence has not muted any alerts for longer than the
+	// threshold configured on the controller.
+	SilenceConditionUnused = "Unused"
+
+	// SilenceStatePending means the silence has been created, but its start time has not been reached yet.
+	SilenceStatePending = "pending"
+	// SilenceStateActive means the silence currently mutes matching alerts.
+	SilenceStateActive = "active"
+	// SilenceStateExpired means the silence has ended.
+	SilenceStateExpired = "expired"
+)
+
+// SilencedAlert is an alert which is currently muted by a silence.
+type SilencedAlert struct {
+	// Fingerprint of the alert as reported by Alertmanager.
+	Fingerprint string `json:"fingerprint"`
+	// AlertName is the value of the "alertname" label of the alert.
+	// +optional
+	AlertName string `json:"alertName,omitempty"`
+	// Name of the Alert object for the alert, if one exists.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Namespace of the Alert object for the alert, if one exists.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // SilenceStatus defines the observed state of Silence
 type SilenceStatus struct {
 	// SilenceId is the unique identifier for this silence (generated by Alertmanager)
 	SilenceId string `json:"silenceID,omitempty"`
-	// Represents the observations of a Memcached's current state.
-	// Memcached.status.conditions.type are: "Available", "Progressing", and "Degraded"
-	// Memcached.status.conditions.status are one of True, False, Unknown.
-	// Memcached.status.conditions.reason the value should be a CamelCase string and producers of specific
-	// condition types may define expected values and meanings for this field, and whether the values
-	// are considered a guaranteed API.
-	// Memcached.status.conditions.Message is a human readable message indicating details about the transition.
-	// For further information see: https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#typical-status-properties
-
+	// State of the silence as reported by Alertmanager, one of "pending", "active" or "expired".
+	// +optional
+	State string `json:"state,omitempty"`
+	// UpdatedAt is the time at which the silence was last updated in Alertmanager.
+	// +optional
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+	// StartsAt is the absolute start time of the silence, resolved from the spec.
+	// +optional
+	StartsAt *metav1.Time `json:"startsAt,omitempty"`
+	// EndsAt is the absolute end time of the silence, resolved from the spec.
+	// +optional
+	EndsAt *metav1.Time `json:"endsAt,omitempty"`
+	// SilencedAlerts is the number of alerts which are currently muted by the silence.
+	// +optional
+	SilencedAlerts int32 `json:"silencedAlerts,omitempty"`
+	// Alerts lists the alerts which are currently muted by the silence. The list is truncated for silences muting
+	// a large number of alerts, SilencedAlerts always contains the full count.
+	// +optional
+	Alerts []SilencedAlert `json:"alerts,omitempty"`
+	// UnusedSince is the time since which the active silence has not muted any alerts.
+	// It is unset while the silence mutes alerts.
+	// +optional
+	UnusedSince *metav1.Time `json:"unusedSince,omitempty"`
+	// ObservedGeneration is the generation of the spec that was last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest observations of the silence's state.
+	// Known condition types are "Ready", "Synced", "Expired", "Drifted" and "Unused".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 }
 
@@ -57,6 +162,12 @@ type SilenceStatus struct {
 // +kubebuilder:subresource:status
 
 // Silence is the Schema for the silences API
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Creator",type=string,JSONPath=`.spec.createdBy`
+// +kubebuilder:printcolumn:name="Comment",type=string,JSONPath=`.spec.comment`
+// +kubebuilder:printcolumn:name="Alerts",type=integer,JSONPath=`.status.silencedAlerts`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=1
+// +kubebuilder:printcolumn:name="Ends",type=date,JSONPath=`.status.endsAt`,priority=1
 type Silence struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
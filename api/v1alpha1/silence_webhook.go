@@ -0,0 +1,314 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var silencelog = logf.Log.WithName("silence-resource")
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (r *Silence) SetupWebhookWithManager(mgr ctrl.Manager, defaulter *SilenceCustomDefaulter, validator *SilenceCustomValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(defaulter).
+		WithValidator(validator).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-alertmanager-prometheus-io-alertmanager-prometheus-io-v1alpha1-silence,mutating=true,failurePolicy=fail,sideEffects=None,groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=silences,verbs=create;update,versions=v1alpha1,name=msilence.kb.io,admissionReviewVersions=v1
+
+// SilenceCustomDefaulter fills in the creator of a Silence from the Kubernetes user making the request,
+// so that the audit trail in Alertmanager shows who actually created the silence.
+type SilenceCustomDefaulter struct {
+	// EnforceCreatedBy rejects silences whose creator differs from the user making the request.
+	EnforceCreatedBy bool
+	// ControllerNamespace is the namespace of the operator. Its service accounts may set any creator,
+	// since the operator imports silences created by other users in Alertmanager.
+	ControllerNamespace string
+}
+
+var _ webhook.CustomDefaulter = &SilenceCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (d *SilenceCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	silence, spec, err := silenceObjectSpec(obj)
+	if err != nil {
+		return err
+	}
+	silencelog.V(5).Info("default", "name", silence.GetName(), "namespace", silence.GetNamespace())
+
+	// silences mirrored from Alertmanager keep the creator recorded there
+	if silence.GetDeletionTimestamp() != nil || mirroredFromAlertmanager(ctx, d.ControllerNamespace, silence) {
+		return nil
+	}
+
+	if spec.StartsAt.IsZero() {
+		spec.StartsAt = silence.GetCreationTimestamp()
+		if spec.StartsAt.IsZero() {
+			spec.StartsAt = metav1.NewTime(time.Now().Truncate(time.Second))
+		}
+	}
+
+	resource := GroupVersion.WithResource("silences").GroupResource()
+	if _, ok := obj.(*ClusterSilence); ok {
+		resource = GroupVersion.WithResource("clustersilences").GroupResource()
+	}
+	return d.defaultCreatedBy(ctx, resource, silence.GetName(), &spec.CreatedBy)
+}
+
+// defaultCreatedBy sets the creator to the user making the request if it is empty. If EnforceCreatedBy is set,
+// other creators are rejected, unless the object already had that creator or the operator makes the request.
+func (d *SilenceCustomDefaulter) defaultCreatedBy(ctx context.Context, resource schema.GroupResource, name string,
+	createdBy *string) error {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	username := req.UserInfo.Username
+	if *createdBy == "" {
+		*createdBy = username
+		return nil
+	}
+	if !d.EnforceCreatedBy || *createdBy == username || isControllerServiceAccount(d.ControllerNamespace, req.UserInfo.Groups) {
+		return nil
+	}
+
+	// existing objects may keep the creator they were admitted with
+	if req.Operation == admissionv1.Update {
+		oldObj := struct {
+			Spec struct {
+				CreatedBy string `json:"createdBy"`
+			} `json:"spec"`
+		}{}
+		if err := json.Unmarshal(req.OldObject.Raw, &oldObj); err != nil {
+			return err
+		}
+		if oldObj.Spec.CreatedBy == *createdBy {
+			return nil
+		}
+	}
+
+	return apierrors.NewForbidden(resource, name,
+		field.Forbidden(field.NewPath("spec", "createdBy"), fmt.Sprintf("must be empty or %q", username)))
+}
+
+// isControllerServiceAccount returns true if the groups of the user making the request contain the service accounts
+// of the controller namespace.
+func isControllerServiceAccount(controllerNamespace string, groups []string) bool {
+	if controllerNamespace == "" {
+		return false
+	}
+	for _, g := range groups {
+		if g == "system:serviceaccounts:"+controllerNamespace {
+			return true
+		}
+	}
+	return false
+}
+
+// mirroredFromAlertmanager returns true for the Silence objects the operator mirrors from Alertmanager: they are
+// labelled as owned by Alertmanager, live in the controller namespace and are written by the operator itself.
+// The label alone is not sufficient, since anyone who can create Silence objects can set it.
+func mirroredFromAlertmanager(ctx context.Context, controllerNamespace string, silence metav1.Object) bool {
+	if silence.GetLabels()[SilenceOwnerLabel] != SilenceOwnerAlertmanager || silence.GetNamespace() != controllerNamespace {
+		return false
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	return isControllerServiceAccount(controllerNamespace, req.UserInfo.Groups)
+}
+
+// +kubebuilder:webhook:path=/validate-alertmanager-prometheus-io-alertmanager-prometheus-io-v1alpha1-silence,mutating=false,failurePolicy=fail,sideEffects=None,groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=silences,verbs=create;update,versions=v1alpha1,name=vsilence.kb.io,admissionReviewVersions=v1
+
+// SilenceCustomValidator rejects Silence objects which Alertmanager would not accept, so that mistakes surface
+// when the object is applied instead of in the status later on.
+type SilenceCustomValidator struct {
+	// MaxDuration limits how long a silence may last. If zero, the duration is not limited.
+	MaxDuration time.Duration
+	// ControllerNamespace is the namespace of the operator. The silences its service accounts mirror from
+	// Alertmanager into this namespace are not validated, since Alertmanager has already accepted them.
+	ControllerNamespace string
+}
+
+var _ webhook.CustomValidator = &SilenceCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *SilenceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	silence, spec, err := silenceObjectSpec(obj)
+	if err != nil {
+		return nil, err
+	}
+	silencelog.V(5).Info("validate create", "name", silence.GetName(), "namespace", silence.GetNamespace())
+
+	return v.validateSilence(ctx, obj, silence, *spec)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *SilenceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	silence, spec, err := silenceObjectSpec(newObj)
+	if err != nil {
+		return nil, err
+	}
+	_, oldSpec, err := silenceObjectSpec(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	silencelog.V(5).Info("validate update", "name", silence.GetName(), "namespace", silence.GetNamespace())
+
+	// objects which already exist must not get stuck, e.g. when the controller removes the finalizer
+	if silence.GetDeletionTimestamp() != nil || apiequality.Semantic.DeepEqual(oldSpec, spec) {
+		return nil, nil
+	}
+
+	return v.validateSilence(ctx, newObj, silence, *spec)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *SilenceCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *SilenceCustomValidator) validateSilence(ctx context.Context, obj runtime.Object, silence metav1.Object,
+	spec SilenceSpec) (admission.Warnings, error) {
+	// silences mirrored from Alertmanager have already been accepted by it
+	if mirroredFromAlertmanager(ctx, v.ControllerNamespace, silence) {
+		return nil, nil
+	}
+
+	var warnings admission.Warnings
+	allErrs := validateSilenceMatchers(spec)
+
+	specPath := field.NewPath("spec")
+	startsAt := spec.StartsAt.Time
+	if startsAt.IsZero() {
+		startsAt = silence.GetCreationTimestamp().Time
+	}
+	if startsAt.IsZero() {
+		startsAt = time.Now()
+	}
+	endsAt := spec.EndsAt.Time
+	switch {
+	case spec.Duration != nil && !endsAt.IsZero():
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("duration"), "only one of endsAt and duration may be set"))
+	case spec.Duration == nil && endsAt.IsZero():
+		allErrs = append(allErrs, field.Required(specPath.Child("endsAt"), "one of endsAt and duration must be set"))
+	case spec.Duration != nil:
+		endsAt = startsAt.Add(spec.Duration.Duration)
+		if spec.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("duration"), spec.Duration.Duration.String(), "must be positive"))
+		}
+	case !endsAt.After(startsAt):
+		allErrs = append(allErrs, field.Invalid(specPath.Child("endsAt"), spec.EndsAt, "must be after startsAt"))
+	}
+
+	if len(allErrs) == 0 {
+		if v.MaxDuration > 0 && endsAt.Sub(startsAt) > v.MaxDuration {
+			allErrs = append(allErrs, field.Invalid(specPath, endsAt.Sub(startsAt).String(),
+				fmt.Sprintf("the silence must not last longer than %s", v.MaxDuration)))
+		}
+		if endsAt.Before(time.Now()) {
+			warnings = append(warnings, fmt.Sprintf("the silence has already ended at %s", endsAt.Format(time.RFC3339)))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		kind := GroupVersion.WithKind("Silence").GroupKind()
+		if _, ok := obj.(*ClusterSilence); ok {
+			kind = GroupVersion.WithKind("ClusterSilence").GroupKind()
+		}
+		return warnings, apierrors.NewInvalid(kind, silence.GetName(), allErrs)
+	}
+	return warnings, nil
+}
+
+// silenceObjectSpec returns the metadata and spec of a Silence or ClusterSilence, which share the webhooks.
+func silenceObjectSpec(obj runtime.Object) (metav1.Object, *SilenceSpec, error) {
+	switch silence := obj.(type) {
+	case *Silence:
+		return silence, &silence.Spec, nil
+	case *ClusterSilence:
+		return silence, &silence.Spec, nil
+	}
+	return nil, nil, fmt.Errorf("expected a Silence or ClusterSilence object but got %T", obj)
+}
+
+// validateSilenceMatchers checks that the silence has at least one matcher, that all regular expressions compile and
+// that the silence does not match every alert, which Alertmanager would refuse.
+func validateSilenceMatchers(spec SilenceSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	matchersPath := field.NewPath("spec", "matchers")
+
+	if len(spec.MatchLabels) == 0 && len(spec.Matchers) == 0 {
+		return append(allErrs, field.Required(matchersPath, "at least one of matchLabels and matchers must be set"))
+	}
+
+	// a matcher which matches the empty string also matches all alerts without the label
+	matchesEverything := true
+	for _, v := range spec.MatchLabels {
+		if v != "" {
+			matchesEverything = false
+		}
+	}
+	for i, m := range spec.Matchers {
+		matchesEmpty := false
+		switch m.MatchType {
+		case MatchEqual, "":
+			matchesEmpty = m.Value == ""
+		case MatchNotEqual:
+			matchesEmpty = m.Value != ""
+		case MatchRegexp, MatchNotRegexp:
+			// Alertmanager anchors regular expressions at both ends
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(matchersPath.Index(i).Child("value"), m.Value,
+					fmt.Sprintf("invalid regular expression: %s", err)))
+				continue
+			}
+			matchesEmpty = re.MatchString("") == (m.MatchType == MatchRegexp)
+		}
+		if !matchesEmpty {
+			matchesEverything = false
+		}
+	}
+
+	if matchesEverything && len(allErrs) == 0 {
+		allErrs = append(allErrs, field.Invalid(matchersPath, spec.Matchers,
+			"at least one matcher must not match the empty string, otherwise the silence matches all alerts"))
+	}
+	return allErrs
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+var _ = Describe("Silence Webhook", func() {
+	var validator *alertmanagerprometheusiov1alpha1.SilenceCustomValidator
+	var silence *alertmanagerprometheusiov1alpha1.Silence
+
+	requestContext := func(operation admissionv1.Operation, username string, groups []string, oldObj *alertmanagerprometheusiov1alpha1.Silence) context.Context {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			UserInfo:  authenticationv1.UserInfo{Username: username, Groups: groups},
+		}}
+		if oldObj != nil {
+			raw, err := json.Marshal(oldObj)
+			Expect(err).NotTo(HaveOccurred())
+			req.OldObject = runtime.RawExtension{Raw: raw}
+		}
+		return admission.NewContextWithRequest(ctx, req)
+	}
+
+	BeforeEach(func() {
+		validator = &alertmanagerprometheusiov1alpha1.SilenceCustomValidator{MaxDuration: 24 * time.Hour}
+		silence = &alertmanagerprometheusiov1alpha1.Silence{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-silence", Namespace: "default"},
+			Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+				MatchLabels: map[string]string{"alertname": "KubeJobFailed"},
+				Duration:    &metav1.Duration{Duration: time.Hour},
+				Comment:     "testing",
+			},
+		}
+	})
+
+	Context("When creating Silence under Defaulting Webhook", func() {
+		var defaulter *alertmanagerprometheusiov1alpha1.SilenceCustomDefaulter
+
+		BeforeEach(func() {
+			defaulter = &alertmanagerprometheusiov1alpha1.SilenceCustomDefaulter{ControllerNamespace: "alert-operator"}
+		})
+
+		It("Should set createdBy and startsAt", func() {
+			Expect(defaulter.Default(requestContext(admissionv1.Create, "jane", nil, nil), silence)).To(Succeed())
+			Expect(silence.Spec.CreatedBy).To(Equal("jane"))
+			Expect(silence.Spec.StartsAt.Time).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		It("Should keep an explicit createdBy unless enforced", func() {
+			silence.Spec.CreatedBy = "john"
+			Expect(defaulter.Default(requestContext(admissionv1.Create, "jane", nil, nil), silence)).To(Succeed())
+			Expect(silence.Spec.CreatedBy).To(Equal("john"))
+
+			defaulter.EnforceCreatedBy = true
+			err := defaulter.Default(requestContext(admissionv1.Create, "jane", nil, nil), silence)
+			Expect(err).To(MatchError(ContainSubstring("spec.createdBy")))
+		})
+
+		It("Should allow the operator to set any createdBy when enforced", func() {
+			defaulter.EnforceCreatedBy = true
+			silence.Spec.CreatedBy = "ui-user"
+			ctx := requestContext(admissionv1.Create, "system:serviceaccount:alert-operator:controller-manager",
+				[]string{"system:serviceaccounts", "system:serviceaccounts:alert-operator"}, nil)
+			Expect(defaulter.Default(ctx, silence)).To(Succeed())
+			Expect(silence.Spec.CreatedBy).To(Equal("ui-user"))
+		})
+
+		It("Should only reject changes of createdBy on update when enforced", func() {
+			defaulter.EnforceCreatedBy = true
+			silence.Spec.CreatedBy = "john"
+			updated := silence.DeepCopy()
+			updated.Spec.Comment = "changed"
+			Expect(defaulter.Default(requestContext(admissionv1.Update, "jane", nil, silence), updated)).To(Succeed())
+
+			updated.Spec.CreatedBy = "joe"
+			err := defaulter.Default(requestContext(admissionv1.Update, "jane", nil, silence), updated)
+			Expect(err).To(MatchError(ContainSubstring("spec.createdBy")))
+		})
+
+		It("Should enforce createdBy for silences labelled as mirrored from Alertmanager by other users", func() {
+			defaulter.EnforceCreatedBy = true
+			silence.Namespace = "team-a"
+			silence.Labels = map[string]string{alertmanagerprometheusiov1alpha1.SilenceOwnerLabel: alertmanagerprometheusiov1alpha1.SilenceOwnerAlertmanager}
+			silence.Spec.CreatedBy = "admin"
+			err := defaulter.Default(requestContext(admissionv1.Create, "jane", []string{"team-a"}, nil), silence)
+			Expect(err).To(MatchError(ContainSubstring("spec.createdBy")))
+
+			By("also in the controller namespace")
+			silence.Namespace = "alert-operator"
+			err = defaulter.Default(requestContext(admissionv1.Create, "jane", []string{"team-a"}, nil), silence)
+			Expect(err).To(MatchError(ContainSubstring("spec.createdBy")))
+		})
+
+		It("Should keep the creator of silences mirrored from Alertmanager by the operator", func() {
+			defaulter.EnforceCreatedBy = true
+			silence.Namespace = "alert-operator"
+			silence.Labels = map[string]string{alertmanagerprometheusiov1alpha1.SilenceOwnerLabel: alertmanagerprometheusiov1alpha1.SilenceOwnerAlertmanager}
+			ctx := requestContext(admissionv1.Create, "system:serviceaccount:alert-operator:controller-manager",
+				[]string{"system:serviceaccounts", "system:serviceaccounts:alert-operator"}, nil)
+			Expect(defaulter.Default(ctx, silence)).To(Succeed())
+			Expect(silence.Spec.CreatedBy).To(BeEmpty())
+			Expect(silence.Spec.StartsAt.IsZero()).To(BeTrue())
+		})
+	})
+
+	Context("When creating Silence under Validating Webhook", func() {
+		It("Should admit a valid silence", func() {
+			_, err := validator.ValidateCreate(ctx, silence)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a silence without matchers", func() {
+			silence.Spec.MatchLabels = nil
+			_, err := validator.ValidateCreate(ctx, silence)
+			Expect(err).To(MatchError(ContainSubstring("spec.matchers")))
+		})
+
+		It("Should deny a silence which ends before it starts", func() {
+			silence.Spec.Duration = nil
+			silence.Spec.StartsAt = metav1.NewTime(time.Now().Add(time.Hour))
+			silence.Spec.EndsAt = metav1.NewTime(time.Now())
+			_, err := validator.ValidateCreate(ctx, silence)
+			Expect(err).To(MatchError(ContainSubstring("must be after startsAt")))
+		})
+
+		It("Should deny invalid regular expressions", func() {
+			silence.Spec.Matchers = []alertmanagerprometheusiov1alpha1.Matcher{{Name: "job", Value: "node-(exporter", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp}}
+			_, err := validator.ValidateCreate(ctx, silence)
+			Expect(err).To(MatchError(ContainSubstring("invalid regular expression")))
+		})
+
+		It("Should deny silences which match all alerts", func() {
+			silence.Spec.MatchLabels = nil
+			silence.Spec.Matchers = []alertmanagerprometheusiov1alpha1.Matcher{
+				{Name: "alertname", Value: ".*", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp},
+				{Name: "severity", Value: "none", MatchType: alertmanagerprometheusiov1alpha1.MatchNotEqual},
+			}
+			_, err := validator.ValidateCreate(ctx, silence)
+			Expect(err).To(MatchError(ContainSubstring("matches all alerts")))
+
+			silence.Spec.Matchers[0].Value = ".+"
+			_, err = validator.ValidateCreate(ctx, silence)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny silences longer than the maximum duration", func() {
+			silence.Spec.Duration = &metav1.Duration{Duration: 48 * time.Hour}
+			_, err := validator.ValidateCreate(ctx, silence)
+			Expect(err).To(MatchError(ContainSubstring("must not last longer than 24h0m0s")))
+
+			validator.MaxDuration = 0
+			_, err = validator.ValidateCreate(ctx, silence)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should warn about silences which have already ended", func() {
+			silence.Spec.StartsAt = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			warnings, err := validator.ValidateCreate(ctx, silence)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+		})
+	})
+
+	Context("When creating ClusterSilence under Validating Webhook", func() {
+		It("Should validate it like a Silence", func() {
+			clusterSilence := &alertmanagerprometheusiov1alpha1.ClusterSilence{
+				ObjectMeta: metav1.ObjectMeta{Name: "webhook-clustersilence"},
+				Spec:       silence.Spec,
+			}
+			_, err := validator.ValidateCreate(ctx, clusterSilence)
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterSilence.Spec.MatchLabels = nil
+			_, err = validator.ValidateCreate(ctx, clusterSilence)
+			Expect(err).To(MatchError(ContainSubstring(`ClusterSilence.alertmanager.prometheus.io.alertmanager.prometheus.io "webhook-clustersilence" is invalid`)))
+		})
+	})
+
+	Context("When creating RecurringSilence under the Webhooks", func() {
+		var recurringSilence *alertmanagerprometheusiov1alpha1.RecurringSilence
+
+		BeforeEach(func() {
+			recurringSilence = &alertmanagerprometheusiov1alpha1.RecurringSilence{
+				ObjectMeta: metav1.ObjectMeta{Name: "webhook-recurringsilence", Namespace: "default"},
+				Spec: alertmanagerprometheusiov1alpha1.RecurringSilenceSpec{
+					Schedule:    "0 2 * * *",
+					Duration:    metav1.Duration{Duration: time.Hour},
+					MatchLabels: map[string]string{"alertname": "KubeJobFailed"},
+					Comment:     "nightly maintenance",
+				},
+			}
+		})
+
+		It("Should enforce createdBy like for a Silence", func() {
+			defaulter := &alertmanagerprometheusiov1alpha1.RecurringSilenceCustomDefaulter{
+				SilenceCustomDefaulter: &alertmanagerprometheusiov1alpha1.SilenceCustomDefaulter{ControllerNamespace: "alert-operator"},
+			}
+			Expect(defaulter.Default(requestContext(admissionv1.Create, "jane", nil, nil), recurringSilence)).To(Succeed())
+			Expect(recurringSilence.Spec.CreatedBy).To(Equal("jane"))
+
+			By("rejecting another creator when enforced")
+			defaulter.EnforceCreatedBy = true
+			recurringSilence.Spec.CreatedBy = "admin"
+			err := defaulter.Default(requestContext(admissionv1.Create, "jane", nil, nil), recurringSilence)
+			Expect(err).To(MatchError(ContainSubstring("spec.createdBy")))
+		})
+
+		It("Should admit a valid recurring silence", func() {
+			recurringValidator := &alertmanagerprometheusiov1alpha1.RecurringSilenceCustomValidator{SilenceCustomValidator: validator}
+			_, err := recurringValidator.ValidateCreate(ctx, recurringSilence)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny invalid matchers, schedules and durations", func() {
+			recurringValidator := &alertmanagerprometheusiov1alpha1.RecurringSilenceCustomValidator{SilenceCustomValidator: validator}
+			recurringSilence.Spec.MatchLabels = nil
+			recurringSilence.Spec.Matchers = []alertmanagerprometheusiov1alpha1.Matcher{{Name: "alertname", Value: ".*", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp}}
+			recurringSilence.Spec.Schedule = "every night"
+			recurringSilence.Spec.TimeZone = "Mars/Olympus_Mons"
+			recurringSilence.Spec.Duration = metav1.Duration{Duration: 48 * time.Hour}
+			recurringSilence.Spec.CreateBefore = &metav1.Duration{Duration: -time.Minute}
+			_, err := recurringValidator.ValidateCreate(ctx, recurringSilence)
+			Expect(err).To(MatchError(ContainSubstring(`RecurringSilence.alertmanager.prometheus.io.alertmanager.prometheus.io "webhook-recurringsilence" is invalid`)))
+			Expect(err).To(MatchError(ContainSubstring("matches all alerts")))
+			Expect(err).To(MatchError(ContainSubstring("spec.schedule")))
+			Expect(err).To(MatchError(ContainSubstring("spec.timeZone")))
+			Expect(err).To(MatchError(ContainSubstring("must not last longer than 24h0m0s")))
+			Expect(err).To(MatchError(ContainSubstring("spec.createBefore")))
+
+			By("admitting updates which do not change the spec")
+			updated := recurringSilence.DeepCopy()
+			updated.Labels = map[string]string{"team": "a"}
+			_, err = recurringValidator.ValidateUpdate(ctx, recurringSilence, updated)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When updating Silence under Validating Webhook", func() {
+		It("Should admit updates which do not change the spec", func() {
+			silence.Spec.MatchLabels = nil
+			updated := silence.DeepCopy()
+			updated.Finalizers = nil
+			_, err := validator.ValidateUpdate(ctx, silence, updated)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should admit silences mirrored from Alertmanager by the operator", func() {
+			validator.ControllerNamespace = "default"
+			updated := silence.DeepCopy()
+			updated.Labels = map[string]string{alertmanagerprometheusiov1alpha1.SilenceOwnerLabel: alertmanagerprometheusiov1alpha1.SilenceOwnerAlertmanager}
+			updated.Spec.Duration = &metav1.Duration{Duration: 365 * 24 * time.Hour}
+			operatorCtx := requestContext(admissionv1.Update, "system:serviceaccount:default:controller-manager",
+				[]string{"system:serviceaccounts", "system:serviceaccounts:default"}, silence)
+			_, err := validator.ValidateUpdate(operatorCtx, silence, updated)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should validate silences labelled as mirrored from Alertmanager by other users", func() {
+			validator.ControllerNamespace = "alert-operator"
+			silence.Namespace = "team-a"
+			silence.Labels = map[string]string{alertmanagerprometheusiov1alpha1.SilenceOwnerLabel: alertmanagerprometheusiov1alpha1.SilenceOwnerAlertmanager}
+			silence.Spec.MatchLabels = nil
+			silence.Spec.Matchers = []alertmanagerprometheusiov1alpha1.Matcher{{Name: "alertname", Value: ".*", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp}}
+			tenantCtx := requestContext(admissionv1.Create, "jane", []string{"team-a"}, nil)
+			_, err := validator.ValidateCreate(tenantCtx, silence)
+			Expect(err).To(MatchError(ContainSubstring("matches all alerts")))
+
+			By("also in the controller namespace")
+			silence.Namespace = "alert-operator"
+			_, err = validator.ValidateCreate(tenantCtx, silence)
+			Expect(err).To(MatchError(ContainSubstring("matches all alerts")))
+		})
+
+		It("Should deny invalid changes to the spec", func() {
+			updated := silence.DeepCopy()
+			updated.Spec.EndsAt = metav1.NewTime(time.Now().Add(time.Hour))
+			_, err := validator.ValidateUpdate(ctx, silence, updated)
+			Expect(err).To(MatchError(ContainSubstring("only one of endsAt and duration")))
+		})
+	})
+
+	Context("When creating Silence through the API server", func() {
+		It("Should reject invalid silences", func() {
+			silence.Spec.MatchLabels = nil
+			Expect(k8sClient.Create(ctx, silence)).NotTo(Succeed())
+		})
+
+		It("Should reject invalid silences labelled as mirrored from Alertmanager", func() {
+			silence.Labels = map[string]string{alertmanagerprometheusiov1alpha1.SilenceOwnerLabel: alertmanagerprometheusiov1alpha1.SilenceOwnerAlertmanager}
+			silence.Spec.MatchLabels = nil
+			silence.Spec.Matchers = []alertmanagerprometheusiov1alpha1.Matcher{{Name: "alertname", Value: ".*", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp}}
+			Expect(k8sClient.Create(ctx, silence)).To(MatchError(ContainSubstring("matches all alerts")))
+		})
+
+		It("Should record the user who created the silence", func() {
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+			Expect(silence.Spec.CreatedBy).NotTo(BeEmpty())
+			Expect(silence.Spec.StartsAt.IsZero()).To(BeFalse())
+			Expect(k8sClient.Delete(ctx, silence)).To(Succeed())
+		})
+	})
+})
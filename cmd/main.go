@@ -18,6 +18,7 @@ package main
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
 	"net/http"
@@ -69,6 +70,26 @@ func main() {
 	var alertmanagerBaseUrl string
 	var alertmanagerBearerAuthorizationToken string
 	var syncInterval string
+	var prometheusBaseUrl string
+	var prometheusBearerAuthorizationToken string
+	var prometheusTLSSkipVerify bool
+	var prometheusCAFile string
+	var alertSyncInterval string
+	var alertResolvedRetention time.Duration
+	var alertSource string
+	var alertNamespacePlacement bool
+	var alertNamespaceLabel string
+	var alertReceiverAddr string
+	var alertInvolvedObjectLabels string
+	var alertReplicaLabels string
+	var alertLoadRules bool
+	var alertReceiverBearerToken string
+	var silenceImportPolicy string
+	var silenceDeletionMaxAttempts int
+	var silenceMaxDuration time.Duration
+	var silenceEnforceCreatedBy bool
+	var silenceNamespaceLabel string
+	var silenceUnusedThreshold time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -82,7 +103,37 @@ func main() {
 	flag.StringVar(&controllerNamespace, "namespace", "", "The namespace in which the controller runs and creates objects.")
 	flag.StringVar(&alertmanagerBaseUrl, "alertmanager-base-url", "http://localhost:9091", "The address at which Alertmanager listens for requests.")
 	flag.StringVar(&alertmanagerBearerAuthorizationToken, "alertmanager-bearer-authorization-token", "", "Bearer Authorization for authenticating with Alertmanager (optional)")
-	flag.StringVar(&syncInterval, "sync-interval", "15s", "The interval at which alerts should be loaded from the Prometheus API (as a Go duration).")
+	flag.StringVar(&syncInterval, "sync-interval", "15s", "The interval at which silences should be loaded from the Alertmanager API (as a Go duration).")
+	flag.StringVar(&silenceImportPolicy, "silence-import-policy", controller.SilenceImportPolicyReadOnly, "How silences created in Alertmanager are handled: "+
+		"'import-read-only' mirrors them as read-only Silence objects, 'adopt' imports them as Silence objects managed by Kubernetes, "+
+		"'ignore' leaves them alone and 'expire-unmanaged' expires them in Alertmanager.")
+	flag.IntVar(&silenceDeletionMaxAttempts, "silence-deletion-max-attempts", 10, "How often deleting a silence from Alertmanager is retried "+
+		"(with exponential backoff) before the finalizer of the Silence object is removed anyway.")
+	flag.DurationVar(&silenceMaxDuration, "silence-max-duration", 0, "The longest duration of a Silence accepted by the validating webhook (as a Go duration). Use 0 for no limit.")
+	flag.BoolVar(&silenceEnforceCreatedBy, "silence-enforce-created-by", false, "If set, the mutating webhook rejects Silences whose createdBy differs from the user creating them.")
+	flag.StringVar(&silenceNamespaceLabel, "silence-namespace-label", "namespace", "The alert label which Silences outside of the controller namespace are restricted to. "+
+		"Use an empty string to allow Silences in any namespace to match all alerts.")
+	flag.DurationVar(&silenceUnusedThreshold, "silence-unused-threshold", 24*time.Hour, "How long an active Silence may mute no alerts "+
+		"before its Unused condition is set (as a Go duration). Use 0 to disable the condition.")
+	flag.StringVar(&prometheusBaseUrl, "prometheus-base-url", "http://localhost:9090", "The address at which Prometheus listens for requests.")
+	flag.StringVar(&prometheusBearerAuthorizationToken, "prometheus-bearer-authorization-token", "", "Bearer Authorization for authenticating with Prometheus (optional)")
+	flag.BoolVar(&prometheusTLSSkipVerify, "prometheus-tls-skip-verify", false, "If set, the TLS certificate presented by Prometheus is not verified.")
+	flag.StringVar(&prometheusCAFile, "prometheus-ca-file", "", "Path to a PEM-encoded CA bundle for verifying the TLS certificate presented by Prometheus (optional)")
+	flag.StringVar(&alertSyncInterval, "alert-sync-interval", "15s", "The interval at which alerts should be loaded from the alert source (as a Go duration).")
+	flag.StringVar(&alertSource, "alert-source", controller.AlertSourcePrometheus, "Where alerts should be loaded from, either 'prometheus' (/api/v1/alerts) or 'alertmanager' (/api/v2/alerts). "+
+		"Use 'none' to only load alerts from AlertSource objects.")
+	flag.BoolVar(&alertNamespacePlacement, "alert-namespace-placement", false, "If set, Alert objects are created in the namespace named by the alert's namespace label (if it exists) instead of the controller namespace.")
+	flag.StringVar(&alertNamespaceLabel, "alert-namespace-label", "namespace", "The alert label which contains the namespace for placing Alert objects (see --alert-namespace-placement).")
+	flag.StringVar(&alertReceiverAddr, "alert-receiver-bind-address", "0", "The address the Alertmanager webhook receiver binds to, "+
+		"for pushing alert notifications to the operator (e.g. ':8082'). Use the default '0' to disable the receiver.")
+	flag.StringVar(&alertReceiverBearerToken, "alert-receiver-bearer-token", "", "Bearer Authorization token which Alertmanager must send to the webhook receiver (optional)")
+	flag.StringVar(&alertInvolvedObjectLabels, "alert-involved-object-labels", controller.DefaultInvolvedObjectLabels,
+		"Comma-separated mapping of alert labels to the kinds of objects they name (label=[group/]version/Kind), for populating status.involvedObjects of Alerts.")
+	flag.StringVar(&alertReplicaLabels, "alert-replica-labels", "", "Comma-separated list of labels which distinguish HA replicas reporting the same alert "+
+		"(e.g. 'prometheus_replica'). They are removed from alerts, and alerts which only differ in them are merged into one Alert object.")
+	flag.BoolVar(&alertLoadRules, "alert-load-rules", true, "If set, the alerting rules are loaded from Prometheus (/api/v1/rules) "+
+		"and recorded in status.rule of the Alerts, together with the RuleHealthy condition.")
+	flag.DurationVar(&alertResolvedRetention, "alert-resolved-retention", 15*time.Minute, "How long resolved alerts are kept (as a Go duration). Use 0 to delete them immediately.")
 
 	opts := zap.Options{
 		Development: true,
@@ -102,11 +153,39 @@ func main() {
 		// }
 	}
 
-	// syncAlertsChannel, err := setupChannelWithInterval(syncInterval)
-	// if err != nil {
-	// 	setupLog.Error(err, "Failed to setup sync interval")
-	// 	os.Exit(1)
-	// }
+	switch alertSource {
+	case controller.AlertSourcePrometheus, controller.AlertSourceAlertmanager, controller.AlertSourceNone:
+	default:
+		setupLog.Error(fmt.Errorf("unknown alert source '%s'", alertSource), "Invalid alert source, must be 'prometheus', 'alertmanager' or 'none'.")
+		os.Exit(1)
+	}
+	// pushed alerts are only resolved by the periodic resync if they are missed, which needs an alert source
+	if alertSource == controller.AlertSourceNone && alertReceiverAddr != "0" {
+		setupLog.Error(fmt.Errorf("--alert-receiver-bind-address requires --alert-source"),
+			"The alert receiver cannot be used with alert source 'none'.")
+		os.Exit(1)
+	}
+
+	involvedObjectLabels, err := controller.ParseInvolvedObjectLabels(alertInvolvedObjectLabels)
+	if err != nil {
+		setupLog.Error(err, "Invalid involved object label mapping")
+		os.Exit(1)
+	}
+
+	switch silenceImportPolicy {
+	case controller.SilenceImportPolicyReadOnly, controller.SilenceImportPolicyAdopt,
+		controller.SilenceImportPolicyIgnore, controller.SilenceImportPolicyExpireUnmanaged:
+	default:
+		setupLog.Error(fmt.Errorf("unknown silence import policy '%s'", silenceImportPolicy),
+			"Invalid silence import policy, must be 'import-read-only', 'adopt', 'ignore' or 'expire-unmanaged'.")
+		os.Exit(1)
+	}
+
+	syncAlertsChannel, err := setupChannelWithInterval(alertSyncInterval)
+	if err != nil {
+		setupLog.Error(err, "Failed to setup alert sync interval")
+		os.Exit(1)
+	}
 
 	syncSilencesChannel, err := setupChannelWithInterval(syncInterval)
 	if err != nil {
@@ -117,6 +196,12 @@ func main() {
 	// TOOD: make tlsSkipVerify configurable
 	alertmanagerClient := newAlertmanagerClient(alertmanagerBaseUrl, alertmanagerBearerAuthorizationToken, true)
 
+	prometheusHTTPClient, err := newPrometheusHTTPClient(prometheusTLSSkipVerify, prometheusCAFile)
+	if err != nil {
+		setupLog.Error(err, "Failed to setup Prometheus client")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -180,27 +265,108 @@ func main() {
 		os.Exit(1)
 	}
 
-	// if err = (&controller.AlertReconciler{
-	// 	Client:              mgr.GetClient(),
-	// 	Scheme:              mgr.GetScheme(),
-	// 	ControllerNamespace: controllerNamespace,
-	// 	PrometheusBaseURL:   prometheusBaseURL,
-	// 	SyncChannel: syncAlertsChannel,
-	// }).SetupWithManager(mgr); err != nil {
-	// 	setupLog.Error(err, "unable to create controller", "controller", "Alert")
-	// 	os.Exit(1)
-	// }
+	if err := controller.CheckInvolvedObjectLabels(mgr.GetRESTMapper(), involvedObjectLabels); err != nil {
+		setupLog.Error(err, "Invalid involved object label mapping")
+		os.Exit(1)
+	}
+
+	alertReconciler := &controller.AlertReconciler{
+		Client:                             mgr.GetClient(),
+		Scheme:                             mgr.GetScheme(),
+		ControllerNamespace:                controllerNamespace,
+		AlertSource:                        alertSource,
+		AlertmanagerClient:                 alertmanagerClient,
+		PrometheusBaseURL:                  prometheusBaseUrl,
+		PrometheusBearerAuthorizationToken: prometheusBearerAuthorizationToken,
+		PrometheusHTTPClient:               prometheusHTTPClient,
+		ResolvedRetention:                  alertResolvedRetention,
+		NamespacePlacement:                 alertNamespacePlacement,
+		NamespaceLabel:                     alertNamespaceLabel,
+		SyncChannel:                        syncAlertsChannel,
+		Recorder:                           mgr.GetEventRecorderFor("alert-controller"),
+		InvolvedObjectLabels:               involvedObjectLabels,
+		APIReader:                          mgr.GetAPIReader(),
+		ReplicaLabels:                      controller.ParseReplicaLabels(alertReplicaLabels),
+		LoadRules:                          alertLoadRules,
+	}
+	if err = alertReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Alert")
+		os.Exit(1)
+	}
+	if err = (&controller.AlertSourceReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Alerts:    alertReconciler,
+		Namespace: controllerNamespace,
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AlertSource")
+		os.Exit(1)
+	}
+	if alertReceiverAddr != "0" {
+		if err = mgr.Add(&controller.AlertReceiver{
+			Reconciler:  alertReconciler,
+			BindAddress: alertReceiverAddr,
+			BearerToken: alertReceiverBearerToken,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up Alertmanager webhook receiver")
+			os.Exit(1)
+		}
+	}
 
 	if err = (&controller.SilenceReconciler{
-		Client:             mgr.GetClient(),
-		Scheme:             mgr.GetScheme(),
-		Namespace:          controllerNamespace,
-		SyncChannel:        syncSilencesChannel,
-		AlertmanagerClient: alertmanagerClient,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Namespace:           controllerNamespace,
+		SyncChannel:         syncSilencesChannel,
+		AlertmanagerClient:  alertmanagerClient,
+		Recorder:            mgr.GetEventRecorderFor("silence-controller"),
+		MaxDeletionAttempts: silenceDeletionMaxAttempts,
+		ImportPolicy:        silenceImportPolicy,
+		NamespaceLabel:      silenceNamespaceLabel,
+		UnusedThreshold:     silenceUnusedThreshold,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Silence")
 		os.Exit(1)
 	}
+	if err = (&controller.ClusterSilenceReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Namespace: controllerNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterSilence")
+		os.Exit(1)
+	}
+	if err = (&controller.RecurringSilenceReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RecurringSilence")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		silenceDefaulter := &alertmanagerprometheusiov1alpha1.SilenceCustomDefaulter{
+			EnforceCreatedBy:    silenceEnforceCreatedBy,
+			ControllerNamespace: controllerNamespace,
+		}
+		silenceValidator := &alertmanagerprometheusiov1alpha1.SilenceCustomValidator{
+			MaxDuration:         silenceMaxDuration,
+			ControllerNamespace: controllerNamespace,
+		}
+		if err = (&alertmanagerprometheusiov1alpha1.Silence{}).SetupWebhookWithManager(mgr, silenceDefaulter, silenceValidator); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Silence")
+			os.Exit(1)
+		}
+		if err = (&alertmanagerprometheusiov1alpha1.ClusterSilence{}).SetupWebhookWithManager(mgr, silenceDefaulter, silenceValidator); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterSilence")
+			os.Exit(1)
+		}
+		if err = (&alertmanagerprometheusiov1alpha1.RecurringSilence{}).SetupWebhookWithManager(mgr, silenceDefaulter, silenceValidator); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "RecurringSilence")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -255,13 +421,13 @@ func setupChannelWithInterval(interval string) (chan event.GenericEvent, error)
 
 func newAlertmanagerClient(baseUrl string, bearerAuthorizationToken string, tlsSkipVerify bool) *alertmanagerapi.APIClient {
 	// if necessary, disable tls certificate verification
-	tr := &http.Transport{		
+	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: tlsSkipVerify,
 		},
 	}
 	httpClient := &http.Client{Transport: tr}
-	
+
 	cfg := alertmanagerapi.NewConfiguration()
 	// TODO: leave URL alone, set cfg.{Host,Scheme} instead
 	cfg.Servers[0].URL = baseUrl + "/api/v2"
@@ -272,7 +438,35 @@ func newAlertmanagerClient(baseUrl string, bearerAuthorizationToken string, tlsS
 		cfg.AddDefaultHeader("Authorization", "Bearer "+bearerAuthorizationToken)
 	}
 	cfg.HTTPClient = httpClient
-	
+
 	// TODO: test the client before returning it
 	return alertmanagerapi.NewAPIClient(cfg)
 }
+
+func newPrometheusHTTPClient(tlsSkipVerify bool, caFile string) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: tlsSkipVerify,
+	}
+
+	// if necessary, trust the CA bundle in addition to the system roots
+	if caFile != "" {
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read CA file '%s': %w", caFile, err)
+		}
+		caPool, err := x509.SystemCertPool()
+		if err != nil {
+			caPool = x509.NewCertPool()
+		}
+		if !caPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No valid PEM certificates found in '%s'", caFile)
+		}
+		tlsConfig.RootCAs = caPool
+	}
+
+	tr := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+	return &http.Client{Transport: tr, Timeout: 30 * time.Second}, nil
+}
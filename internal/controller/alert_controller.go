@@ -18,15 +18,22 @@ package controller
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -36,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+	"github.com/jacksgt/alert-operator/internal/alertmanagerapi"
 )
 
 // AlertReconciler reconciles a Alert object
@@ -43,13 +51,75 @@ type AlertReconciler struct {
 	client.Client
 	Scheme              *runtime.Scheme
 	ControllerNamespace string
-	PrometheusBaseURL   string
-	SyncChannel         chan event.GenericEvent
+	// AlertSource selects where alerts are loaded from, either AlertSourcePrometheus or AlertSourceAlertmanager.
+	// AlertSourceNone disables the built-in source, leaving alerts to be loaded from AlertSource objects.
+	AlertSource string
+	// AlertmanagerClient is used for loading alerts when AlertSource is AlertSourceAlertmanager.
+	AlertmanagerClient *alertmanagerapi.APIClient
+	PrometheusBaseURL  string
+	// PrometheusBearerAuthorizationToken is sent as Bearer Authorization header to Prometheus (optional).
+	PrometheusBearerAuthorizationToken string
+	// PrometheusHTTPClient is used for all requests to Prometheus. If nil, http.DefaultClient is used.
+	PrometheusHTTPClient *http.Client
+	// ResolvedRetention is the time for which resolved alerts are kept before they are deleted.
+	// If zero, Alert objects are deleted as soon as the alert is no longer active.
+	ResolvedRetention time.Duration
+	// NamespacePlacement enables creating Alert objects in the namespace named by the alert's NamespaceLabel
+	// (if that namespace exists). Otherwise, all Alert objects are created in the ControllerNamespace.
+	NamespacePlacement bool
+	// NamespaceLabel is the alert label which contains the target namespace (usually "namespace").
+	NamespaceLabel string
+	SyncChannel    chan event.GenericEvent
+	// Recorder emits Events when an alert starts firing, changes its value or resolves (optional).
+	Recorder record.EventRecorder
+	// InvolvedObjectLabels maps alert labels to the kinds of objects they name, for populating status.involvedObjects.
+	InvolvedObjectLabels []InvolvedObjectLabel
+	// APIReader looks up the objects involved in alerts directly from the API server, so no informers are started for
+	// their kinds. If nil, the Client is used.
+	APIReader client.Reader
+	// ReplicaLabels are the labels which distinguish the HA replicas reporting the same alert (e.g. "prometheus_replica").
+	// They are removed from the alerts, and alerts which only differ in them are merged into one Alert object.
+	ReplicaLabels []string
+	// LoadRules enables loading the alerting rules from Prometheus, which are recorded in status.rule of the alerts.
+	// Applies to AlertSources as well.
+	LoadRules bool
+
+	// legacyAlertsMigrated is set once Alert objects created by previous versions have been migrated.
+	legacyAlertsMigrated bool
 }
 
+const (
+	// managedByLabel marks the Alert objects that are owned (and garbage collected) by this controller.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "alert-operator"
+	// alertNameLabel contains the original name of the alert (if it is a valid label value).
+	alertNameLabel = "alertmanager.prometheus.io/alertname"
+	// fingerprintLabel contains the fingerprint of the alert, which allows looking up Alert objects by fingerprint.
+	fingerprintLabel = "alertmanager.prometheus.io/fingerprint"
+	// sourceLabel contains the name of the alert source from which the status of the Alert object is taken.
+	sourceLabel = "alertmanager.prometheus.io/source"
+
+	alertStateResolved = "resolved"
+
+	// legacySinceLayout is the format of time.Time.String(), which was used for the deprecated status.since field.
+	legacySinceLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+	// AlertSourcePrometheus loads alerts from the Prometheus /api/v1/alerts endpoint.
+	AlertSourcePrometheus = "prometheus"
+	// AlertSourceAlertmanager loads alerts from the Alertmanager /api/v2/alerts endpoint.
+	AlertSourceAlertmanager = "alertmanager"
+	// AlertSourceNone disables loading alerts with the AlertReconciler, only AlertSource objects are polled.
+	AlertSourceNone = "none"
+)
+
 // +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=alerts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=alerts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=alerts/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods;services;nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -70,48 +140,329 @@ func (r *AlertReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	log.Info("syncing all alerts")
 
-	alerts, err := getActiveAlerts(ctx, r.PrometheusBaseURL)
+	if !r.legacyAlertsMigrated {
+		if err := r.migrateLegacyAlerts(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.legacyAlertsMigrated = true
+	}
+
+	if r.AlertSource == AlertSourceNone {
+		return ctrl.Result{}, nil
+	}
+	source := r.BuiltinSource()
+
+	alerts, err := r.getActiveAlerts(ctx)
 	if err != nil {
 		// error talking to prometheus, retry later
 		return ctrl.Result{}, err
 	}
 
-	log.Info(fmt.Sprintf("Got %d alerts from Prometheus", len(alerts)))
+	log.Info(fmt.Sprintf("Got %d alerts from %s", len(alerts), r.AlertSource))
+	alerts = mergeAlertReplicas(alerts, r.ReplicaLabels)
 
-	for _, a := range alerts {
-		var alertObj = alertmanagerprometheusiov1alpha1.Alert{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      generateAlertName(a),
-				Namespace: r.ControllerNamespace,
-			},
+	var rules *alertRules
+	if r.LoadRules && source == AlertSourcePrometheus {
+		rules, err = fetchAlertRules(ctx, r.PrometheusHTTPClient, r.PrometheusBaseURL, r.PrometheusBearerAuthorizationToken, r.ReplicaLabels)
+		if err != nil {
+			// the alerts are still useful without their rules
+			log.Error(err, "Unable to load alerting rules from Prometheus")
 		}
+	}
+
+	// keep track of the alerts that are still active, everything else is garbage collected below
+	activeAlerts := map[types.NamespacedName]bool{}
 
-		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, &alertObj, func() error {
-			return nil
+	for _, a := range alerts {
+		key, err := r.upsertAlert(ctx, source, a, func(status *alertmanagerprometheusiov1alpha1.AlertStatus) {
+			status.State = a.State
+			status.Annotations = a.Annotations
+			status.Labels = a.Labels
+			status.ActiveAt = optionalTime(a.ActiveAt)
+			status.Since = ""
+			status.Value = a.Value
+			status.Receivers = a.Receivers
+			status.GeneratorURL = a.GeneratorURL
+			status.StartsAt = optionalTime(a.StartsAt)
+			status.EndsAt = optionalTime(a.EndsAt)
+			status.UpdatedAt = optionalTime(a.UpdatedAt)
+			status.SilencedBy = a.SilencedBy
+			status.InhibitedBy = a.InhibitedBy
+			status.Replicas = a.Replicas
+			setAlertRule(status, rules, a)
 		})
+		activeAlerts[key] = true
 		if err != nil {
-			log.Error(err, "Unable to create Alert", alertObj.ObjectMeta.Name)
+			log.Error(err, "Unable to create or update Alert", "name", key.Name, "namespace", key.Namespace)
 			continue
 		}
+	}
 
-		// TODO: consider using something like CreateOrUpdate
-		// Set status
-		alertObj.Status.State = a.State
-		alertObj.Status.Annotations = a.Annotations
-		alertObj.Status.Labels = a.Labels
-		alertObj.Status.Since = a.ActiveAt.String()
-		alertObj.Status.Value = a.Value
+	if err := r.garbageCollectAlerts(ctx, source, activeAlerts); err != nil {
+		return ctrl.Result{}, err
+	}
 
-		err = r.updateAlertStatus(&alertObj)
-		if err != nil {
-			log.Error(err, "Unable to set alert status")
+	return ctrl.Result{}, nil
+}
+
+// BuiltinSource returns the name under which alerts loaded by the AlertReconciler itself (and pushed to the
+// AlertReceiver) are recorded in status.sources of the Alert objects.
+func (r *AlertReconciler) BuiltinSource() string {
+	if r.AlertSource == "" {
+		return AlertSourcePrometheus
+	}
+	return r.AlertSource
+}
+
+// upsertAlert creates or updates the Alert object for an alert which is active in the given source. setStatus fills in
+// the status fields from the alert, the fields which are common to all active alerts (fingerprint, last seen, resolved,
+// sources) are set by upsertAlert. When several sources report the same alert, setStatus is only applied for the first
+// of them, so the status does not flip between the (possibly slightly different) reports of e.g. HA replicas.
+func (r *AlertReconciler) upsertAlert(ctx context.Context, source string, a Alert,
+	setStatus func(*alertmanagerprometheusiov1alpha1.AlertStatus)) (types.NamespacedName, error) {
+	alertObj := alertmanagerprometheusiov1alpha1.Alert{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      generateAlertName(a),
+			Namespace: r.alertNamespace(ctx, a),
+		},
+	}
+	key := client.ObjectKeyFromObject(&alertObj)
+	if a.Fingerprint == "" {
+		a.Fingerprint = alertFingerprint(a.Labels)
+	}
+
+	var sources []string
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, &alertObj, func() error {
+		// an alert which has resolved starts over with the source which reports it again
+		if alertObj.Status.ResolvedAt == nil {
+			sources = alertObj.Status.Sources
+		}
+		sources = addSource(sources, source)
+		setLabel(&alertObj, managedByLabel, managedByValue)
+		setLabel(&alertObj, alertNameLabel, alertNameLabelValue(a))
+		setLabel(&alertObj, fingerprintLabel, a.Fingerprint)
+		setLabel(&alertObj, sourceLabel, sourceLabelValue(sources[0]))
+		return nil
+	})
+	if err != nil {
+		return key, fmt.Errorf("Failed to create Alert: %w", err)
+	}
+
+	previous := *alertObj.Status.DeepCopy()
+	if sources[0] == source {
+		setStatus(&alertObj.Status)
+	}
+	alertObj.Status.Sources = sources
+	alertObj.Status.Fingerprint = a.Fingerprint
+	alertObj.Status.LastSeen = &metav1.Time{Time: time.Now()}
+	alertObj.Status.ResolvedAt = nil
+	if !involvedObjectsResolved(previous, alertObj.Status.Labels) {
+		alertObj.Status.InvolvedObjects = r.resolveInvolvedObjects(ctx, alertObj.Status.Labels)
+	}
+
+	if err := r.updateAlertStatus(&alertObj); err != nil {
+		return key, err
+	}
+	r.recordAlertTransition(previous, &alertObj)
+	return key, nil
+}
+
+// resolveAlert handles an alert which is no longer active in the given source: its Alert object (if any) is released
+// by the source, see releaseAlert. The object is garbage collected by the next sync otherwise.
+func (r *AlertReconciler) resolveAlert(ctx context.Context, source string, a Alert, resolvedAt time.Time) error {
+	alertObj := alertmanagerprometheusiov1alpha1.Alert{}
+	key := types.NamespacedName{Name: generateAlertName(a), Namespace: r.alertNamespace(ctx, a)}
+	if err := r.Get(ctx, key, &alertObj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if alertObj.Labels[managedByLabel] != managedByValue {
+		return nil
+	}
+
+	if alertObj.Status.ResolvedAt != nil {
+		return nil
+	}
+
+	// the alert is still active as long as other replicas report it
+	remaining := slices.DeleteFunc(slices.Clone(alertObj.Status.Replicas), func(replica string) bool {
+		return slices.Contains(a.Replicas, replica)
+	})
+	if len(a.Replicas) > 0 && len(remaining) > 0 {
+		alertObj.Status.Replicas = remaining
+		return r.updateAlertStatus(&alertObj)
+	}
+	return r.releaseAlert(ctx, source, &alertObj, resolvedAt)
+}
+
+// releaseAlert handles an Alert object whose alert is no longer reported by the given source. If other sources still
+// report the alert, only the source is removed from the object. Otherwise, the object is marked as resolved, or
+// deleted right away if resolved alerts are not retained.
+func (r *AlertReconciler) releaseAlert(ctx context.Context, source string,
+	alertObj *alertmanagerprometheusiov1alpha1.Alert, resolvedAt time.Time) error {
+	sources := alertObj.Status.Sources
+	// objects created before sources were recorded belong to the built-in source
+	if len(sources) == 0 && source != r.BuiltinSource() {
+		return nil
+	}
+	if len(sources) > 0 && !slices.Contains(sources, source) {
+		return nil
+	}
+
+	if remaining := slices.DeleteFunc(slices.Clone(sources), func(s string) bool { return s == source }); len(remaining) > 0 {
+		if value := sourceLabelValue(remaining[0]); alertObj.Labels[sourceLabel] != value {
+			setLabel(alertObj, sourceLabel, value)
+			if err := r.Update(ctx, alertObj); err != nil {
+				return fmt.Errorf("Failed to update Alert: %w", err)
+			}
+		}
+		alertObj.Status.Sources = remaining
+		return r.updateAlertStatus(alertObj)
+	}
+
+	if r.ResolvedRetention <= 0 {
+		return r.deleteResolvedAlert(ctx, alertObj)
+	}
+	return r.markAlertResolved(ctx, alertObj, resolvedAt)
+}
+
+// markAlertResolved sets the state of the Alert object to resolved.
+func (r *AlertReconciler) markAlertResolved(ctx context.Context, alertObj *alertmanagerprometheusiov1alpha1.Alert, resolvedAt time.Time) error {
+	migrateAlertStatus(&alertObj.Status)
+	alertObj.Status.State = alertStateResolved
+	alertObj.Status.ResolvedAt = &metav1.Time{Time: resolvedAt}
+	if err := r.updateAlertStatus(alertObj); err != nil {
+		return err
+	}
+	r.recordAlertEvent(alertObj, corev1.EventTypeNormal, reasonAlertResolved, "resolved")
+	return nil
+}
+
+// deleteResolvedAlert deletes the Alert object of an alert which has just resolved, when resolved alerts are not retained.
+func (r *AlertReconciler) deleteResolvedAlert(ctx context.Context, alertObj *alertmanagerprometheusiov1alpha1.Alert) error {
+	if err := r.Delete(ctx, alertObj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	r.recordAlertEvent(alertObj, corev1.EventTypeNormal, reasonAlertResolved, "resolved")
+	return nil
+}
+
+// garbageCollectAlerts releases all Alert objects of the given source which are not in its set of active alerts
+// (see releaseAlert), and deletes resolved Alert objects once they have been resolved for longer than the retention period.
+func (r *AlertReconciler) garbageCollectAlerts(ctx context.Context, source string, activeAlerts map[types.NamespacedName]bool) error {
+	log := log.FromContext(ctx)
+
+	listOpts := []client.ListOption{client.MatchingLabels{managedByLabel: managedByValue}}
+	if !r.NamespacePlacement {
+		listOpts = append(listOpts, client.InNamespace(r.ControllerNamespace))
+	}
+
+	alertList := alertmanagerprometheusiov1alpha1.AlertList{}
+	err := r.List(ctx, &alertList, listOpts...)
+	if err != nil {
+		return fmt.Errorf("Failed to list Alerts: %w", err)
+	}
+
+	now := time.Now()
+	for i := range alertList.Items {
+		alertObj := &alertList.Items[i]
+		if activeAlerts[client.ObjectKeyFromObject(alertObj)] {
+			continue
+		}
+
+		// alert is no longer active in this source, mark it as resolved first (unless other sources still report it)
+		if alertObj.Status.ResolvedAt == nil {
+			if err := r.releaseAlert(ctx, source, alertObj, now); err != nil {
+				log.Error(err, "Unable to release alert", "name", alertObj.Name, "source", source)
+			}
+			continue
+		}
+
+		// keep resolved alerts around until the retention period has passed
+		if now.Sub(alertObj.Status.ResolvedAt.Time) < r.ResolvedRetention {
 			continue
 		}
+
+		log.V(5).Info("Deleting resolved alert", "name", alertObj.Name)
+		if err := r.Delete(ctx, alertObj); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Unable to delete resolved alert", "name", alertObj.Name)
+		}
 	}
 
-	// TODO: garbage collect old alerts
+	return nil
+}
 
-	return ctrl.Result{}, nil
+// alertNamespace returns the namespace in which the Alert object for the alert should be placed.
+func (r *AlertReconciler) alertNamespace(ctx context.Context, a Alert) string {
+	if !r.NamespacePlacement {
+		return r.ControllerNamespace
+	}
+
+	namespace := a.Labels[r.NamespaceLabel]
+	if namespace == "" {
+		return r.ControllerNamespace
+	}
+
+	// only place the alert in the namespace if it actually exists, otherwise fall back to our own namespace
+	ns := corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Failed to look up namespace for alert", "namespace", namespace)
+		}
+		return r.ControllerNamespace
+	}
+	if ns.Status.Phase == corev1.NamespaceTerminating {
+		return r.ControllerNamespace
+	}
+
+	return namespace
+}
+
+// migrateLegacyAlerts adopts the Alert objects created by previous versions of the controller (which did not set
+// the managed-by label) and converts their deprecated status fields. Since the naming scheme has changed as well,
+// these objects are subsequently garbage collected like any other alert that is no longer active.
+func (r *AlertReconciler) migrateLegacyAlerts(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	alertList := alertmanagerprometheusiov1alpha1.AlertList{}
+	if err := r.List(ctx, &alertList, client.InNamespace(r.ControllerNamespace)); err != nil {
+		return fmt.Errorf("Failed to list Alerts: %w", err)
+	}
+
+	for i := range alertList.Items {
+		alertObj := &alertList.Items[i]
+		// status.since was only ever written by the controller, so it reliably identifies legacy objects
+		if alertObj.Labels[managedByLabel] == managedByValue || alertObj.Status.Since == "" {
+			continue
+		}
+
+		log.Info("Migrating legacy Alert object", "name", alertObj.Name)
+		setLabel(alertObj, managedByLabel, managedByValue)
+		if err := r.Update(ctx, alertObj); err != nil {
+			return fmt.Errorf("Failed to migrate Alert %s: %w", alertObj.Name, err)
+		}
+		migrateAlertStatus(&alertObj.Status)
+		if err := r.updateAlertStatus(alertObj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateAlertStatus converts the deprecated fields of the status into their current representation.
+func migrateAlertStatus(status *alertmanagerprometheusiov1alpha1.AlertStatus) {
+	if status.Since == "" {
+		return
+	}
+
+	if status.ActiveAt == nil {
+		// strip the monotonic clock reading (if any), it is not part of the layout
+		since, _, _ := strings.Cut(status.Since, " m=")
+		if t, err := time.Parse(legacySinceLayout, since); err == nil {
+			status.ActiveAt = &metav1.Time{Time: t}
+		}
+	}
+	status.Since = ""
 }
 
 func (r *AlertReconciler) updateAlertStatus(a *alertmanagerprometheusiov1alpha1.Alert) error {
@@ -121,49 +472,192 @@ func (r *AlertReconciler) updateAlertStatus(a *alertmanagerprometheusiov1alpha1.
 	return nil
 }
 
+// Generates a unique and stable name for the Alert object based on the alert's name and its fingerprint.
+// The result is a valid RFC 1123 subdomain name (as required for object names).
 func generateAlertName(a Alert) string {
-	alertName := a.Labels["alertname"]
+	fingerprint := a.Fingerprint
+	if fingerprint == "" {
+		fingerprint = alertFingerprint(a.Labels)
+	}
+
+	// According to https://github.com/prometheus/prometheus/blob/d002fad00c20eaad029d6d122bfc513b091f78ad/rules/alerting.go#L394
+	// the "alertname" label should always be set, but we can't rely on it.
+	alertName := sanitizeName(a.Labels["alertname"], validation.DNS1123SubdomainMaxLength-len(fingerprint)-1)
 	if alertName == "" {
-		// According to https://github.com/prometheus/prometheus/blob/d002fad00c20eaad029d6d122bfc513b091f78ad/rules/alerting.go#L394
-		// the "alertname" label should always be set		
-		panic("alertname label is not set!")
+		alertName = "alert"
+	}
+
+	return alertName + "-" + fingerprint
+}
+
+// Computes the fingerprint of an alert based on its full label set.
+// The result is identical to the fingerprint Alertmanager assigns to the alert.
+func alertFingerprint(labels map[string]string) string {
+	labelSet := make(model.LabelSet, len(labels))
+	for k, v := range labels {
+		labelSet[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return labelSet.Fingerprint().String()
+}
+
+// Converts an arbitrary string into a valid RFC 1123 name of at most maxLength characters:
+// all characters are lowercased, (sequences of) other characters are replaced with a single dash
+// and leading or trailing dashes are removed. Can return an empty string.
+func sanitizeName(in string, maxLength int) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(in) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+			dash = false
+		} else if !dash {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	out := strings.Trim(b.String(), "-")
+	if len(out) > maxLength {
+		out = strings.Trim(out[:maxLength], "-")
+	}
+	return out
+}
+
+// Returns the value to be used for the alertname label on the Alert object.
+// Label values are more restricted than alertnames, hence the original value is only used when it is valid.
+func alertNameLabelValue(a Alert) string {
+	alertName := a.Labels["alertname"]
+	if len(validation.IsValidLabelValue(alertName)) == 0 {
+		return alertName
+	}
+	return sanitizeName(alertName, validation.LabelValueMaxLength)
+}
+
+// Returns the value to be used for the source label on the Alert object.
+func sourceLabelValue(source string) string {
+	if len(validation.IsValidLabelValue(source)) == 0 {
+		return source
+	}
+	return sanitizeName(source, validation.LabelValueMaxLength)
+}
+
+// Appends the source to the list of sources, unless it is already contained.
+func addSource(sources []string, source string) []string {
+	if slices.Contains(sources, source) {
+		return sources
+	}
+	return append(slices.Clone(sources), source)
+}
+
+// Converts a timestamp to its API representation, omitting unset timestamps.
+func optionalTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
 	}
-	// TODO: include labels / annotations in calculation as well?
-	data := a.ActiveAt.String()
-	hash := sha256.Sum256([]byte(data))
-	return fmt.Sprintf("%s-%x", alertName, hash[0:8])
+	return &metav1.Time{Time: t}
 }
 
-func getActiveAlerts(ctx context.Context, baseURL string) ([]Alert, error) {
+func (r *AlertReconciler) getActiveAlerts(ctx context.Context) ([]Alert, error) {
+	switch r.AlertSource {
+	case AlertSourceAlertmanager:
+		return r.getAlertmanagerAlerts(ctx)
+	case AlertSourcePrometheus, "":
+		return fetchPrometheusAlerts(ctx, r.PrometheusHTTPClient, r.PrometheusBaseURL, r.PrometheusBearerAuthorizationToken)
+	default:
+		return nil, fmt.Errorf("Unknown alert source '%s'", r.AlertSource)
+	}
+}
+
+func (r *AlertReconciler) getAlertmanagerAlerts(ctx context.Context) ([]Alert, error) {
 	var alerts []Alert
-	// TODO: authentication
-	resp, err := http.Get(baseURL + "/api/v1/alerts")
+	alertsResp, _, err := r.AlertmanagerClient.AlertAPI.GetAlerts(ctx).Execute()
 	if err != nil {
-		return alerts, fmt.Errorf("Error making HTTP request: %w", err)
+		return alerts, fmt.Errorf("Error fetching alerts from Alertmanager: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return alerts, fmt.Errorf("Error reading response body: %w", err)
+	for _, a := range alertsResp {
+		alerts = append(alerts, convertGettableAlert(a))
 	}
+	return alerts, nil
+}
 
-	// Parse the JSON response
+// Converts an alert returned by the Alertmanager API into the same format as alerts returned by Prometheus.
+func convertGettableAlert(in alertmanagerapi.GettableAlert) Alert {
+	receivers := []string{}
+	for _, receiver := range in.GetReceivers() {
+		receivers = append(receivers, receiver.GetName())
+	}
+	status := in.GetStatus()
+
+	return Alert{
+		ActiveAt:     in.GetStartsAt(),
+		Annotations:  in.GetAnnotations(),
+		Labels:       in.GetLabels(),
+		State:        status.GetState(),
+		Fingerprint:  in.GetFingerprint(),
+		Receivers:    receivers,
+		GeneratorURL: in.GetGeneratorURL(),
+		StartsAt:     in.GetStartsAt(),
+		EndsAt:       in.GetEndsAt(),
+		UpdatedAt:    in.GetUpdatedAt(),
+		SilencedBy:   status.GetSilencedBy(),
+		InhibitedBy:  status.GetInhibitedBy(),
+	}
+}
+
+// Loads the active alerts from the /api/v1/alerts endpoint of Prometheus (or a compatible API, e.g. Thanos Ruler).
+// If httpClient is nil, http.DefaultClient is used.
+func fetchPrometheusAlerts(ctx context.Context, httpClient *http.Client, baseURL, bearerToken string) ([]Alert, error) {
 	var alertResponse PrometheusAlertResponse
-	err = json.Unmarshal(body, &alertResponse)
-	if err != nil {
-		return alerts, fmt.Errorf("Error parsing JSON: %w", err)
+	if err := getPrometheusAPI(ctx, httpClient, baseURL+"/api/v1/alerts", bearerToken, &alertResponse); err != nil {
+		return nil, err
 	}
 
 	if alertResponse.Status != "success" {
-		return alerts, fmt.Errorf("Unexpected response status in JSON: '%s'", alertResponse.Status)
+		return nil, fmt.Errorf("Unexpected response status in JSON: '%s'", alertResponse.Status)
 	}
 
 	// All good, return the alerts
 	return alertResponse.Data.Alerts, nil
 }
 
+// Sends a GET request to the Prometheus HTTP API and parses the JSON response into v.
+// If httpClient is nil, http.DefaultClient is used.
+func getPrometheusAPI(ctx context.Context, httpClient *http.Client, url, bearerToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("Error creating HTTP request: %w", err)
+	}
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected HTTP response status from Prometheus: '%s'", resp.Status)
+	}
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Error reading response body: %w", err)
+	}
+
+	// Parse the JSON response
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("Error parsing JSON: %w", err)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *AlertReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// whenever we get an event on this channel, we trigger a sync ("reconciliation") for all alerts
@@ -188,4 +682,19 @@ type Alert struct {
 	Labels      map[string]string `json:"labels"`
 	State       string            `json:"state"`
 	Value       string            `json:"value"`
+
+	// Fingerprint is set by Alertmanager, for other alerts it is computed from the labels.
+	Fingerprint string `json:"-"`
+
+	// The following fields are only populated for alerts loaded from Alertmanager.
+	Receivers    []string  `json:"-"`
+	GeneratorURL string    `json:"-"`
+	StartsAt     time.Time `json:"-"`
+	EndsAt       time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+	SilencedBy   []string  `json:"-"`
+	InhibitedBy  []string  `json:"-"`
+
+	// Replicas is set when replica labels were removed from the alert, see mergeAlertReplicas.
+	Replicas []string `json:"-"`
 }
@@ -18,18 +18,41 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+	"github.com/jacksgt/alert-operator/internal/alertmanagerapi"
 )
 
+// countingReader counts the objects which are looked up through it.
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
 var _ = Describe("Alert Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
@@ -81,4 +104,517 @@ var _ = Describe("Alert Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When syncing all alerts from Prometheus", func() {
+		ctx := context.Background()
+
+		var prometheusAlerts string
+		var prometheus *httptest.Server
+
+		BeforeEach(func() {
+			prometheusAlerts = `[{"activeAt": "2018-07-04T20:27:12.60602144+02:00", "labels": {"alertname": "my-alert"}, "state": "firing", "value": "1e+00"}]`
+			prometheus = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				fmt.Fprintf(w, `{"status": "success", "data": {"alerts": %s}}`, prometheusAlerts)
+			}))
+		})
+
+		AfterEach(func() {
+			prometheus.Close()
+			Expect(k8sClient.DeleteAllOf(ctx, &alertmanagerprometheusiov1alpha1.Alert{},
+				client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+		})
+
+		It("should mark alerts as resolved and garbage collect them", func() {
+			controllerReconciler := &AlertReconciler{
+				Client:              k8sClient,
+				Scheme:              k8sClient.Scheme(),
+				ControllerNamespace: "default",
+				PrometheusBaseURL:   prometheus.URL,
+				ResolvedRetention:   time.Hour,
+			}
+
+			By("creating an Alert object for the active alert")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			Expect(alerts.Items[0].Status.State).To(Equal("firing"))
+
+			By("marking the Alert object as resolved once the alert is gone")
+			prometheusAlerts = `[]`
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			Expect(alerts.Items[0].Status.State).To(Equal(alertStateResolved))
+			Expect(alerts.Items[0].Status.ResolvedAt).NotTo(BeNil())
+
+			By("deleting the Alert object after the retention period")
+			controllerReconciler.ResolvedRetention = time.Nanosecond
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(BeEmpty())
+		})
+
+		It("should merge the alerts of HA replicas into one Alert object", func() {
+			prometheusAlerts = `[
+				{"labels": {"alertname": "my-alert", "prometheus_replica": "prometheus-k8s-0"}, "state": "firing"},
+				{"labels": {"alertname": "my-alert", "prometheus_replica": "prometheus-k8s-1"}, "state": "firing"}
+			]`
+			controllerReconciler := &AlertReconciler{
+				Client:              k8sClient,
+				Scheme:              k8sClient.Scheme(),
+				ControllerNamespace: "default",
+				PrometheusBaseURL:   prometheus.URL,
+				ResolvedRetention:   time.Hour,
+				ReplicaLabels:       []string{"prometheus_replica"},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			Expect(alerts.Items[0].Name).To(Equal("my-alert-f2d4f3d15854f99b"))
+			Expect(alerts.Items[0].Status.Labels).NotTo(HaveKey("prometheus_replica"))
+			Expect(alerts.Items[0].Status.Replicas).To(Equal([]string{"prometheus_replica=prometheus-k8s-0", "prometheus_replica=prometheus-k8s-1"}))
+
+			By("keeping the Alert object active while any replica reports the alert")
+			prometheusAlerts = `[{"labels": {"alertname": "my-alert", "prometheus_replica": "prometheus-k8s-1"}, "state": "firing"}]`
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			Expect(alerts.Items[0].Status.ResolvedAt).To(BeNil())
+			Expect(alerts.Items[0].Status.Replicas).To(Equal([]string{"prometheus_replica=prometheus-k8s-1"}))
+		})
+
+		It("should record the alerting rule of the alerts", func() {
+			prometheusAlerts = `[
+				{"labels": {"alertname": "my-alert", "severity": "critical", "pod": "a"}, "state": "firing"},
+				{"labels": {"alertname": "broken-alert"}, "state": "firing"},
+				{"labels": {"alertname": "unknown-alert"}, "state": "firing"}
+			]`
+			rules := `{"status": "success", "data": {"groups": [{"name": "my-group", "file": "/etc/prometheus/rules/my-rules.yaml", "rules": [
+				{"type": "alerting", "name": "my-alert", "query": "up == 0", "duration": 600, "labels": {"severity": "warning"},
+					"health": "ok", "lastEvaluation": "2024-07-04T20:27:12Z",
+					"alerts": [{"labels": {"alertname": "my-alert", "severity": "warning", "pod": "a"}, "state": "firing"}]},
+				{"type": "alerting", "name": "my-alert", "query": "up == 0", "duration": 60, "labels": {"severity": "critical"},
+					"health": "ok", "lastEvaluation": "2024-07-04T20:27:12Z",
+					"alerts": [{"labels": {"alertname": "my-alert", "severity": "critical", "pod": "a"}, "state": "firing"}]},
+				{"type": "alerting", "name": "broken-alert", "query": "vector(1", "health": "err", "lastError": "parse error"},
+				{"type": "recording", "name": "unknown-alert", "query": "up"}
+			]}]}}`
+			rulesPrometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path == "/api/v1/rules" {
+					fmt.Fprint(w, rules)
+					return
+				}
+				fmt.Fprintf(w, `{"status": "success", "data": {"alerts": %s}}`, prometheusAlerts)
+			}))
+			defer rulesPrometheus.Close()
+			controllerReconciler := &AlertReconciler{
+				Client:              k8sClient,
+				Scheme:              k8sClient.Scheme(),
+				ControllerNamespace: "default",
+				PrometheusBaseURL:   rulesPrometheus.URL,
+				LoadRules:           true,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			alertsByName := map[string]alertmanagerprometheusiov1alpha1.AlertStatus{}
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			for _, alertObj := range alerts.Items {
+				alertsByName[alertObj.Status.Labels["alertname"]] = alertObj.Status
+			}
+			Expect(alertsByName).To(HaveLen(3))
+
+			By("matching the alerts listed by the rule")
+			status := alertsByName["my-alert"]
+			Expect(status.Rule).NotTo(BeNil())
+			Expect(status.Rule.Group).To(Equal("my-group"))
+			Expect(status.Rule.File).To(Equal("/etc/prometheus/rules/my-rules.yaml"))
+			Expect(status.Rule.Query).To(Equal("up == 0"))
+			Expect(status.Rule.For.Duration).To(Equal(time.Minute))
+			Expect(status.Rule.LastEvaluation.Time).To(BeTemporally("==", time.Date(2024, 7, 4, 20, 27, 12, 0, time.UTC)))
+			Expect(meta.IsStatusConditionTrue(status.Conditions, alertmanagerprometheusiov1alpha1.AlertConditionRuleHealthy)).To(BeTrue())
+
+			By("reporting rules which failed to evaluate")
+			status = alertsByName["broken-alert"]
+			Expect(status.Rule).NotTo(BeNil())
+			Expect(status.Rule.LastError).To(Equal("parse error"))
+			condition := meta.FindStatusCondition(status.Conditions, alertmanagerprometheusiov1alpha1.AlertConditionRuleHealthy)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(reasonRuleEvaluationFailed))
+			Expect(condition.Message).To(ContainSubstring("parse error"))
+
+			By("leaving out alerts without a known rule")
+			Expect(alertsByName["unknown-alert"].Rule).To(BeNil())
+			Expect(alertsByName["unknown-alert"].Conditions).To(BeEmpty())
+		})
+
+		It("should place alerts in the namespace named by the alert", func() {
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}})).To(Succeed())
+			prometheusAlerts = `[
+				{"labels": {"alertname": "my-alert", "namespace": "team-a"}, "state": "firing"},
+				{"labels": {"alertname": "my-alert", "namespace": "does-not-exist"}, "state": "firing"}
+			]`
+			controllerReconciler := &AlertReconciler{
+				Client:              k8sClient,
+				Scheme:              k8sClient.Scheme(),
+				ControllerNamespace: "default",
+				PrometheusBaseURL:   prometheus.URL,
+				NamespacePlacement:  true,
+				NamespaceLabel:      "namespace",
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("team-a"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+
+			Expect(k8sClient.DeleteAllOf(ctx, &alertmanagerprometheusiov1alpha1.Alert{},
+				client.InNamespace("team-a"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+		})
+
+		It("should emit Events on the affected pod", func() {
+			involvedObjectLabels, err := ParseInvolvedObjectLabels(DefaultInvolvedObjectLabels)
+			Expect(err).NotTo(HaveOccurred())
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "crashing-pod", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app"}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			})
+			recorder := record.NewFakeRecorder(10)
+			recorder.IncludeObject = true
+			controllerReconciler := &AlertReconciler{
+				Client:               k8sClient,
+				Scheme:               k8sClient.Scheme(),
+				ControllerNamespace:  "default",
+				PrometheusBaseURL:    prometheus.URL,
+				ResolvedRetention:    time.Hour,
+				NamespaceLabel:       "namespace",
+				Recorder:             recorder,
+				InvolvedObjectLabels: involvedObjectLabels,
+				APIReader:            k8sClient,
+			}
+			alertWithValue := func(value string) string {
+				return fmt.Sprintf(`[{"labels": {"alertname": "KubePodCrashLooping", "namespace": "default", "pod": "crashing-pod"},
+					"annotations": {"summary": "Pod is crash looping."}, "state": "firing", "value": %q}]`, value)
+			}
+
+			By("emitting an Event when the alert starts firing")
+			prometheusAlerts = alertWithValue("3")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(reasonAlertFiring),
+				ContainSubstring("Alert KubePodCrashLooping started firing: Pod is crash looping."),
+				ContainSubstring("kind=Pod"),
+			)))
+
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			Expect(alerts.Items[0].Status.InvolvedObjects).To(ContainElement(alertmanagerprometheusiov1alpha1.InvolvedObject{
+				Label:      "pod",
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Namespace:  "default",
+				Name:       "crashing-pod",
+				UID:        pod.UID,
+				Exists:     true,
+			}))
+
+			By("emitting an Event only when the value changes")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+			prometheusAlerts = alertWithValue("4")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("changed its value from 3 to 4")))
+
+			By("emitting an Event when the alert resolves")
+			prometheusAlerts = `[]`
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(reasonAlertResolved), ContainSubstring("kind=Pod"))))
+
+			By("falling back to the Alert object when the pod does not exist")
+			prometheusAlerts = `[{"labels": {"alertname": "KubePodCrashLooping", "namespace": "default", "pod": "gone"}, "state": "firing"}]`
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(reasonAlertFiring), Not(ContainSubstring("kind=Pod")))))
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(ContainElement(HaveField("Status.InvolvedObjects", ContainElement(And(
+				HaveField("Name", "gone"),
+				HaveField("Exists", BeFalse()),
+			)))))
+		})
+
+		It("should leave out involved objects of kinds which are not served", func() {
+			involvedObjectLabels, err := ParseInvolvedObjectLabels("foo=example.com/v1/Foo,namespace=v1/Namespace")
+			Expect(err).NotTo(HaveOccurred())
+			controllerReconciler := &AlertReconciler{
+				Client:               k8sClient,
+				Scheme:               k8sClient.Scheme(),
+				ControllerNamespace:  "default",
+				PrometheusBaseURL:    prometheus.URL,
+				ResolvedRetention:    time.Hour,
+				InvolvedObjectLabels: involvedObjectLabels,
+				APIReader:            k8sClient,
+			}
+			prometheusAlerts = `[{"labels": {"alertname": "FooDown", "namespace": "default", "foo": "my-foo"}, "state": "firing"}]`
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			Expect(alerts.Items[0].Status.InvolvedObjects).To(ConsistOf(HaveField("Kind", "Namespace")))
+		})
+
+		It("should only look up involved objects again while they do not exist", func() {
+			involvedObjectLabels, err := ParseInvolvedObjectLabels("pod=v1/Pod")
+			Expect(err).NotTo(HaveOccurred())
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "not-ready-pod", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app"}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			})
+			reader := &countingReader{Reader: k8sClient}
+			controllerReconciler := &AlertReconciler{
+				Client:               k8sClient,
+				Scheme:               k8sClient.Scheme(),
+				ControllerNamespace:  "default",
+				PrometheusBaseURL:    prometheus.URL,
+				ResolvedRetention:    time.Hour,
+				InvolvedObjectLabels: involvedObjectLabels,
+				APIReader:            reader,
+			}
+			prometheusAlerts = `[{"labels": {"alertname": "KubePodNotReady", "namespace": "default", "pod": "not-ready-pod"}, "state": "firing"}]`
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.gets).To(Equal(1))
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.gets).To(Equal(1))
+
+			By("looking up objects which do not exist on every sync")
+			prometheusAlerts = `[{"labels": {"alertname": "KubePodNotReady", "namespace": "default", "pod": "pending"}, "state": "firing"}]`
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.gets).To(Equal(2))
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.gets).To(Equal(3))
+		})
+	})
+
+	Context("When syncing all alerts from Alertmanager", func() {
+		ctx := context.Background()
+
+		var alertmanager *fakeAlertmanager
+		var controllerReconciler *AlertReconciler
+
+		BeforeEach(func() {
+			alertmanager = newFakeAlertmanager()
+			controllerReconciler = &AlertReconciler{
+				Client:              k8sClient,
+				Scheme:              k8sClient.Scheme(),
+				ControllerNamespace: "default",
+				AlertSource:         AlertSourceAlertmanager,
+				AlertmanagerClient:  alertmanager.Client(),
+				ResolvedRetention:   time.Hour,
+			}
+		})
+
+		AfterEach(func() {
+			alertmanager.Close()
+			Expect(k8sClient.DeleteAllOf(ctx, &alertmanagerprometheusiov1alpha1.Alert{},
+				client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+		})
+
+		alertObject := func(fingerprint string) alertmanagerprometheusiov1alpha1.Alert {
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{fingerprintLabel: fingerprint})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(1))
+			return alerts.Items[0]
+		}
+
+		It("should create Alert objects for active, silenced and inhibited alerts", func() {
+			active := alertmanager.AddAlert("KubeJobFailed")
+			silenced := alertmanager.AddAlert("KubeJobFailed", "silence-1")
+			inhibited := alertmanager.AddAlert("KubeJobFailed")
+			alertmanager.mu.Lock()
+			alertmanager.alerts[0].SetAnnotations(map[string]string{"summary": "Job failed to complete."})
+			alertmanager.alerts[0].SetReceivers([]alertmanagerapi.Receiver{*alertmanagerapi.NewReceiver("team-a")})
+			alertmanager.alerts[0].SetGeneratorURL("http://prometheus/graph")
+			alertmanager.alerts[2].SetStatus(*alertmanagerapi.NewAlertStatus("suppressed", []string{}, []string{active}))
+			alertmanager.mu.Unlock()
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			Expect(alerts.Items).To(HaveLen(3))
+
+			By("converting the active alert")
+			alertObj := alertObject(active)
+			Expect(alertObj.Name).To(Equal("kubejobfailed-" + active))
+			Expect(alertObj.Labels).To(HaveKeyWithValue(alertNameLabel, "KubeJobFailed"))
+			Expect(alertObj.Status.Fingerprint).To(Equal(active))
+			Expect(alertObj.Status.Labels).To(Equal(map[string]string{"alertname": "KubeJobFailed", "instance": "instance-0"}))
+			Expect(alertObj.Status.Annotations).To(HaveKeyWithValue("summary", "Job failed to complete."))
+			Expect(alertObj.Status.State).To(Equal("active"))
+			Expect(alertObj.Status.Receivers).To(Equal([]string{"team-a"}))
+			Expect(alertObj.Status.GeneratorURL).To(Equal("http://prometheus/graph"))
+			Expect(alertObj.Status.StartsAt).NotTo(BeNil())
+			Expect(alertObj.Status.EndsAt).NotTo(BeNil())
+			Expect(alertObj.Status.SilencedBy).To(BeEmpty())
+			Expect(alertObj.Status.InhibitedBy).To(BeEmpty())
+			Expect(alertObj.Status.Sources).To(Equal([]string{AlertSourceAlertmanager}))
+
+			By("converting the silenced alert")
+			alertObj = alertObject(silenced)
+			Expect(alertObj.Status.State).To(Equal("suppressed"))
+			Expect(alertObj.Status.SilencedBy).To(Equal([]string{"silence-1"}))
+			Expect(alertObj.Status.InhibitedBy).To(BeEmpty())
+
+			By("converting the inhibited alert")
+			alertObj = alertObject(inhibited)
+			Expect(alertObj.Status.State).To(Equal("suppressed"))
+			Expect(alertObj.Status.SilencedBy).To(BeEmpty())
+			Expect(alertObj.Status.InhibitedBy).To(Equal([]string{active}))
+
+			By("resolving alerts which Alertmanager no longer reports")
+			alertmanager.mu.Lock()
+			alertmanager.alerts = alertmanager.alerts[1:]
+			alertmanager.mu.Unlock()
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(alertObject(active).Status.State).To(Equal(alertStateResolved))
+			Expect(alertObject(silenced).Status.State).To(Equal("suppressed"))
+		})
+	})
+
+	Context("When parsing involved object label mappings", func() {
+		It("should parse core and grouped kinds", func() {
+			mappings, err := ParseInvolvedObjectLabels("pod=v1/Pod, job_name=batch/v1/Job")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings).To(Equal([]InvolvedObjectLabel{
+				{Label: "pod", GVK: corev1.SchemeGroupVersion.WithKind("Pod")},
+				{Label: "job_name", GVK: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}},
+			}))
+		})
+
+		It("should reject malformed mappings", func() {
+			for _, in := range []string{"pod", "pod=Pod", "=v1/Pod", "pod=a/b/c/Pod"} {
+				_, err := ParseInvolvedObjectLabels(in)
+				Expect(err).To(HaveOccurred(), in)
+			}
+		})
+
+		It("should reject kinds which are not served", func() {
+			mappings, err := ParseInvolvedObjectLabels(DefaultInvolvedObjectLabels)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(CheckInvolvedObjectLabels(k8sClient.RESTMapper(), mappings)).To(Succeed())
+
+			mappings, err = ParseInvolvedObjectLabels("pod=v1/Pod,foo=example.com/v1/Foo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(CheckInvolvedObjectLabels(k8sClient.RESTMapper(), mappings)).To(MatchError(ContainSubstring("example.com/v1, Kind=Foo")))
+		})
+	})
+
+	Context("When merging the alerts of HA replicas", func() {
+		replicaLabels := ParseReplicaLabels(" prometheus_replica, ,replica")
+
+		It("should merge alerts which only differ in their replica labels", func() {
+			activeAt := time.Date(2024, 7, 4, 20, 27, 12, 0, time.UTC)
+			alerts := mergeAlertReplicas([]Alert{
+				{ActiveAt: activeAt, State: "pending", Value: "1e+00",
+					Labels: map[string]string{"alertname": "my-alert", "prometheus_replica": "prometheus-k8s-0"}},
+				{ActiveAt: activeAt.Add(time.Minute), State: "firing", Value: "2e+00",
+					Labels: map[string]string{"alertname": "my-alert", "prometheus_replica": "prometheus-k8s-1"}},
+				{ActiveAt: activeAt, State: "firing", Value: "3e+00",
+					Labels: map[string]string{"alertname": "my-alert", "prometheus_replica": "prometheus-k8s-2"}},
+				{State: "firing", Labels: map[string]string{"alertname": "other-alert"}},
+			}, replicaLabels)
+
+			Expect(replicaLabels).To(Equal([]string{"prometheus_replica", "replica"}))
+			Expect(alerts).To(HaveLen(2))
+			Expect(alerts[0].Labels).To(Equal(map[string]string{"alertname": "my-alert"}))
+			Expect(alerts[0].Fingerprint).To(Equal("f2d4f3d15854f99b"))
+			Expect(alerts[0].Replicas).To(Equal([]string{"prometheus_replica=prometheus-k8s-0",
+				"prometheus_replica=prometheus-k8s-1", "prometheus_replica=prometheus-k8s-2"}))
+			Expect(alerts[0].State).To(Equal("firing"))
+			Expect(alerts[0].Value).To(Equal("2e+00"))
+			Expect(alerts[0].ActiveAt).To(Equal(activeAt))
+			Expect(alerts[1].Labels).To(Equal(map[string]string{"alertname": "other-alert"}))
+			Expect(alerts[1].Replicas).To(BeEmpty())
+		})
+
+		It("should leave alerts alone without replica labels", func() {
+			alerts := []Alert{{Labels: map[string]string{"alertname": "my-alert", "prometheus_replica": "prometheus-k8s-0"}}}
+			Expect(mergeAlertReplicas(alerts, nil)).To(Equal(alerts))
+		})
+	})
+
+	Context("When generating names for Alert objects", func() {
+		It("should generate distinct names for different series of the same alert", func() {
+			activeAt := time.Now()
+			a := Alert{ActiveAt: activeAt, Labels: map[string]string{"alertname": "KubeJobFailed", "job_name": "a"}}
+			b := Alert{ActiveAt: activeAt, Labels: map[string]string{"alertname": "KubeJobFailed", "job_name": "b"}}
+			Expect(generateAlertName(a)).NotTo(Equal(generateAlertName(b)))
+			Expect(generateAlertName(a)).To(Equal(generateAlertName(a)))
+			Expect(generateAlertName(a)).To(HavePrefix("kubejobfailed-"))
+		})
+
+		It("should use the Alertmanager fingerprint", func() {
+			a := Alert{Labels: map[string]string{"alertname": "my-alert"}}
+			Expect(alertFingerprint(a.Labels)).To(Equal("f2d4f3d15854f99b"))
+			Expect(generateAlertName(a)).To(Equal("my-alert-f2d4f3d15854f99b"))
+		})
+
+		It("should generate valid names for malformed alerts", func() {
+			for _, a := range []Alert{
+				{},
+				{Labels: map[string]string{"alertname": ""}},
+				{Labels: map[string]string{"alertname": "..Foo bar/Baz!!__"}},
+				{Labels: map[string]string{"alertname": strings.Repeat("LongAlertName", 50)}},
+			} {
+				name := generateAlertName(a)
+				Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty(), name)
+				Expect(validation.IsValidLabelValue(alertNameLabelValue(a))).To(BeEmpty(), name)
+			}
+		})
+	})
+
+	Context("When migrating Alert objects from previous versions", func() {
+		It("should convert the deprecated since field", func() {
+			status := alertmanagerprometheusiov1alpha1.AlertStatus{
+				Since: "2018-07-04 20:27:12.60602144 +0200 CEST",
+			}
+			migrateAlertStatus(&status)
+			Expect(status.Since).To(BeEmpty())
+			Expect(status.ActiveAt).NotTo(BeNil())
+			Expect(status.ActiveAt.UTC()).To(Equal(time.Date(2018, 7, 4, 18, 27, 12, 606021440, time.UTC)))
+		})
+	})
 })
@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+const (
+	// Reasons for the Events emitted on alert state transitions
+	reasonAlertFiring       = "AlertFiring"
+	reasonAlertValueChanged = "AlertValueChanged"
+	reasonAlertResolved     = "AlertResolved"
+)
+
+// alertFiring returns true for the states in which an alert fires, as reported by Prometheus or Alertmanager.
+func alertFiring(state string) bool {
+	switch state {
+	case alertStateFiring, "active", "suppressed":
+		return true
+	}
+	return false
+}
+
+// recordAlertTransition emits an Event when the alert has started firing or its value has changed.
+func (r *AlertReconciler) recordAlertTransition(previous alertmanagerprometheusiov1alpha1.AlertStatus,
+	alertObj *alertmanagerprometheusiov1alpha1.Alert) {
+	status := alertObj.Status
+	if !alertFiring(status.State) {
+		return
+	}
+
+	switch {
+	case !alertFiring(previous.State) || previous.ResolvedAt != nil:
+		r.recordAlertEvent(alertObj, corev1.EventTypeWarning, reasonAlertFiring, "started firing")
+	case status.Value != "" && status.Value != previous.Value:
+		r.recordAlertEvent(alertObj, corev1.EventTypeWarning, reasonAlertValueChanged,
+			fmt.Sprintf("changed its value from %s to %s", previous.Value, status.Value))
+	}
+}
+
+// recordAlertEvent emits an Event about the alert, attached to the object the alert is about.
+func (r *AlertReconciler) recordAlertEvent(alertObj *alertmanagerprometheusiov1alpha1.Alert,
+	eventType, reason, transition string) {
+	if r.Recorder == nil {
+		return
+	}
+
+	message := fmt.Sprintf("Alert %s %s", alertObj.Status.Labels["alertname"], transition)
+	if summary := alertObj.Status.Annotations["summary"]; summary != "" {
+		message += ": " + summary
+	}
+	r.Recorder.Event(involvedObject(alertObj), eventType, reason, message)
+}
+
+// involvedObject returns the object the Events of the alert are attached to: the first of its involved objects
+// which exists, or the Alert object itself.
+func involvedObject(alertObj *alertmanagerprometheusiov1alpha1.Alert) runtime.Object {
+	for _, ref := range alertObj.Status.InvolvedObjects {
+		if !ref.Exists {
+			continue
+		}
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+		obj.SetNamespace(ref.Namespace)
+		obj.SetName(ref.Name)
+		obj.SetUID(ref.UID)
+		return obj
+	}
+
+	return alertObj
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+// DefaultInvolvedObjectLabels is the default mapping of alert labels to the kinds of objects they name.
+// The order matters: Events about an alert are attached to the first of its involved objects which exists.
+const DefaultInvolvedObjectLabels = "pod=v1/Pod,deployment=apps/v1/Deployment,statefulset=apps/v1/StatefulSet," +
+	"daemonset=apps/v1/DaemonSet,job_name=batch/v1/Job,service=v1/Service,node=v1/Node,namespace=v1/Namespace"
+
+// involvedObjectLookupTimeout bounds the lookup of each involved object, so that a slow API server does not stall the
+// sync of all alerts.
+const involvedObjectLookupTimeout = 5 * time.Second
+
+// InvolvedObjectLabel maps an alert label to the kind of object whose name it contains.
+type InvolvedObjectLabel struct {
+	Label string
+	GVK   schema.GroupVersionKind
+}
+
+// ParseInvolvedObjectLabels parses a comma-separated list of label=[group/]version/Kind mappings,
+// e.g. "pod=v1/Pod,deployment=apps/v1/Deployment".
+func ParseInvolvedObjectLabels(in string) ([]InvolvedObjectLabel, error) {
+	var mappings []InvolvedObjectLabel
+	for _, mapping := range strings.Split(in, ",") {
+		mapping = strings.TrimSpace(mapping)
+		if mapping == "" {
+			continue
+		}
+
+		label, gvk, ok := strings.Cut(mapping, "=")
+		apiVersion, kind, _ := cutLast(gvk, "/")
+		if !ok || label == "" || apiVersion == "" || kind == "" {
+			return nil, fmt.Errorf("Invalid involved object label mapping '%s', expected label=[group/]version/Kind", mapping)
+		}
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid involved object label mapping '%s': %w", mapping, err)
+		}
+		mappings = append(mappings, InvolvedObjectLabel{Label: label, GVK: gv.WithKind(kind)})
+	}
+	return mappings, nil
+}
+
+// CheckInvolvedObjectLabels returns an error for the kinds of the mappings which are not served by the cluster.
+func CheckInvolvedObjectLabels(mapper meta.RESTMapper, mappings []InvolvedObjectLabel) error {
+	var errs []error
+	for _, l := range mappings {
+		if _, err := mapper.RESTMapping(l.GVK.GroupKind(), l.GVK.Version); err != nil {
+			errs = append(errs, fmt.Errorf("Unknown kind %s for label '%s': %w", l.GVK.String(), l.Label, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}
+
+// involvedObjectsResolved returns true if the involved objects recorded in the status of an alert were looked up for
+// the given labels and all of them exist, so they do not have to be looked up again on every sync.
+func involvedObjectsResolved(status alertmanagerprometheusiov1alpha1.AlertStatus, labels map[string]string) bool {
+	if len(status.InvolvedObjects) == 0 || !maps.Equal(status.Labels, labels) {
+		return false
+	}
+	return !slices.ContainsFunc(status.InvolvedObjects, func(o alertmanagerprometheusiov1alpha1.InvolvedObject) bool {
+		return !o.Exists
+	})
+}
+
+// resolveInvolvedObjects looks up the objects named by the labels of an alert according to InvolvedObjectLabels.
+// Namespaced objects are looked up in the namespace given by the NamespaceLabel of the alert.
+// Kinds which are not served by the cluster and objects which cannot be looked up are left out.
+func (r *AlertReconciler) resolveInvolvedObjects(ctx context.Context, labels map[string]string) []alertmanagerprometheusiov1alpha1.InvolvedObject {
+	log := log.FromContext(ctx)
+
+	namespaceLabel := r.NamespaceLabel
+	if namespaceLabel == "" {
+		namespaceLabel = "namespace"
+	}
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+
+	var involvedObjects []alertmanagerprometheusiov1alpha1.InvolvedObject
+	for _, l := range r.InvolvedObjectLabels {
+		name := labels[l.Label]
+		if name == "" {
+			continue
+		}
+
+		mapping, err := r.RESTMapper().RESTMapping(l.GVK.GroupKind(), l.GVK.Version)
+		if err != nil {
+			log.V(5).Info("Unable to map involved object kind", "kind", l.GVK.String(), "error", err.Error())
+			continue
+		}
+		key := types.NamespacedName{Name: name}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if key.Namespace = labels[namespaceLabel]; key.Namespace == "" {
+				continue
+			}
+		}
+
+		involvedObject := alertmanagerprometheusiov1alpha1.InvolvedObject{
+			Label:      l.Label,
+			APIVersion: l.GVK.GroupVersion().String(),
+			Kind:       l.GVK.Kind,
+			Namespace:  key.Namespace,
+			Name:       key.Name,
+		}
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(l.GVK)
+		lookupCtx, cancel := context.WithTimeout(ctx, involvedObjectLookupTimeout)
+		err = reader.Get(lookupCtx, key, obj)
+		cancel()
+		switch {
+		case err == nil:
+			involvedObject.UID = obj.GetUID()
+			involvedObject.Exists = true
+		case apierrors.IsNotFound(err):
+		default:
+			log.Error(err, "Failed to look up object involved in alert", "kind", l.GVK.Kind, "name", key.Name, "namespace", key.Namespace)
+			continue
+		}
+		involvedObjects = append(involvedObjects, involvedObject)
+	}
+	return involvedObjects
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+const (
+	// AlertReceiverPath is the path at which the AlertReceiver accepts notifications from Alertmanager.
+	AlertReceiverPath = "/api/v1/webhook"
+
+	// maxWebhookPayloadSize limits the size of the notifications accepted by the AlertReceiver.
+	maxWebhookPayloadSize = 10 << 20
+
+	alertStateFiring = "firing"
+)
+
+// AlertReceiver is an Alertmanager webhook receiver (https://prometheus.io/docs/alerting/latest/configuration/#webhook_config).
+// Alertmanager pushes notifications about firing and resolved alerts to it, which are applied to the Alert objects
+// right away. The periodic sync of the AlertReconciler remains in place to catch up on missed notifications.
+type AlertReceiver struct {
+	// Reconciler creates, updates and resolves the Alert objects.
+	Reconciler *AlertReconciler
+	// BindAddress is the address the HTTP server listens on, e.g. ":8082".
+	BindAddress string
+	// BearerToken must be sent as Bearer Authorization header by Alertmanager (optional).
+	BearerToken string
+}
+
+var _ manager.Runnable = &AlertReceiver{}
+var _ manager.LeaderElectionRunnable = &AlertReceiver{}
+
+// WebhookMessage is the payload Alertmanager sends to webhook receivers.
+type WebhookMessage struct {
+	Version  string         `json:"version"`
+	GroupKey string         `json:"groupKey"`
+	Status   string         `json:"status"`
+	Receiver string         `json:"receiver"`
+	Alerts   []WebhookAlert `json:"alerts"`
+}
+
+// WebhookAlert is a single alert in the payload Alertmanager sends to webhook receivers.
+type WebhookAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Start runs the HTTP server until the context is cancelled.
+func (r *AlertReceiver) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("alert-receiver")
+
+	mux := http.NewServeMux()
+	mux.Handle("POST "+AlertReceiverPath, r)
+	server := &http.Server{
+		Addr:              r.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("Starting Alertmanager webhook receiver", "address", r.BindAddress, "path", AlertReceiverPath)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("Alertmanager webhook receiver failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// NeedLeaderElection ensures that only the leader applies notifications, like the AlertReconciler itself.
+func (r *AlertReceiver) NeedLeaderElection() bool {
+	return true
+}
+
+// ServeHTTP applies a notification from Alertmanager to the Alert objects. If any alert could not be applied, an
+// error status is returned so Alertmanager retries the notification.
+func (r *AlertReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	log := log.FromContext(req.Context()).WithName("alert-receiver")
+
+	if r.BearerToken != "" {
+		expected := "Bearer " + r.BearerToken
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte(expected)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	msg := WebhookMessage{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxWebhookPayloadSize)).Decode(&msg); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing JSON: %s", err), http.StatusBadRequest)
+		return
+	}
+	log.V(5).Info("Received notification from Alertmanager", "groupKey", msg.GroupKey, "status", msg.Status, "alerts", len(msg.Alerts))
+
+	var errs []error
+	for _, wa := range msg.Alerts {
+		if err := r.applyAlert(req.Context(), msg.Receiver, wa); err != nil {
+			log.Error(err, "Unable to apply alert from Alertmanager", "fingerprint", wa.Fingerprint)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		http.Error(w, errors.Join(errs...).Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// applyAlert creates or updates the Alert object of a firing alert, or resolves it.
+// The fingerprint Alertmanager assigns to the alert is not used: like for the alerts loaded from the alert source, it
+// is computed from the labels (without the replica labels), so both end up in the same Alert object.
+func (r *AlertReceiver) applyAlert(ctx context.Context, receiver string, wa WebhookAlert) error {
+	a := Alert{
+		ActiveAt:     wa.StartsAt,
+		Annotations:  wa.Annotations,
+		Labels:       wa.Labels,
+		State:        wa.Status,
+		GeneratorURL: wa.GeneratorURL,
+		StartsAt:     wa.StartsAt,
+		EndsAt:       wa.EndsAt,
+	}
+	a = stripReplicaLabels(a, r.Reconciler.ReplicaLabels)
+
+	if wa.Status == alertStateResolved {
+		resolvedAt := wa.EndsAt
+		if resolvedAt.IsZero() {
+			resolvedAt = time.Now()
+		}
+		return r.Reconciler.resolveAlert(ctx, r.Reconciler.BuiltinSource(), a, resolvedAt)
+	}
+
+	_, err := r.Reconciler.upsertAlert(ctx, r.Reconciler.BuiltinSource(), a, func(status *alertmanagerprometheusiov1alpha1.AlertStatus) {
+		// notifications carry less information than the alert source, the other fields are left to the periodic sync
+		migrateAlertStatus(status)
+		if status.State == "" || status.State == alertStateResolved {
+			status.State = alertStateFiring
+			status.Replicas = nil
+		}
+		status.Annotations = a.Annotations
+		status.Labels = a.Labels
+		if status.ActiveAt == nil {
+			status.ActiveAt = optionalTime(a.ActiveAt)
+		}
+		status.GeneratorURL = a.GeneratorURL
+		status.StartsAt = optionalTime(a.StartsAt)
+		status.EndsAt = optionalTime(a.EndsAt)
+		if receiver != "" && !slices.Contains(status.Receivers, receiver) {
+			status.Receivers = append(status.Receivers, receiver)
+		}
+		status.Replicas = mergeReplicas(status.Replicas, a.Replicas)
+	})
+	return err
+}
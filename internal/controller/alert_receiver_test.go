@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+var _ = Describe("Alert Receiver", func() {
+	Context("When receiving notifications from Alertmanager", func() {
+		ctx := context.Background()
+
+		var receiver *AlertReceiver
+
+		BeforeEach(func() {
+			receiver = &AlertReceiver{
+				Reconciler: &AlertReconciler{
+					Client:              k8sClient,
+					Scheme:              k8sClient.Scheme(),
+					ControllerNamespace: "default",
+					ResolvedRetention:   time.Hour,
+				},
+				BearerToken: "secret",
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &alertmanagerprometheusiov1alpha1.Alert{},
+				client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+		})
+
+		post := func(token, body string) int {
+			req := httptest.NewRequest(http.MethodPost, AlertReceiverPath, strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			receiver.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		notify := func(token, status string) int {
+			body := fmt.Sprintf(`{"version": "4", "groupKey": "{}:{alertname=\"my-alert\"}", "status": %q, "receiver": "alert-operator",
+				"alerts": [{"status": %q, "labels": {"alertname": "my-alert"}, "annotations": {"summary": "Something is wrong"},
+				"startsAt": "2024-07-04T20:27:12Z", "endsAt": "2024-07-04T21:27:12Z", "fingerprint": "3f2a9c1d5e7b8a60"}]}`, status, status)
+			return post(token, body)
+		}
+
+		listAlerts := func() []alertmanagerprometheusiov1alpha1.Alert {
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			return alerts.Items
+		}
+
+		It("should create and resolve Alert objects", func() {
+			By("creating an Alert object for a firing alert")
+			Expect(notify("secret", "firing")).To(Equal(http.StatusOK))
+			Expect(listAlerts()).To(HaveLen(1))
+			alertObj := listAlerts()[0]
+			Expect(alertObj.Name).To(Equal("my-alert-" + alertFingerprint(map[string]string{"alertname": "my-alert"})))
+			Expect(alertObj.Status.State).To(Equal(alertStateFiring))
+			Expect(alertObj.Status.Annotations).To(HaveKeyWithValue("summary", "Something is wrong"))
+			Expect(alertObj.Status.Receivers).To(ConsistOf("alert-operator"))
+
+			By("marking the Alert object as resolved")
+			Expect(notify("secret", "resolved")).To(Equal(http.StatusOK))
+			Expect(listAlerts()).To(HaveLen(1))
+			alertObj = listAlerts()[0]
+			Expect(alertObj.Status.State).To(Equal(alertStateResolved))
+			Expect(alertObj.Status.ResolvedAt.Time).To(BeTemporally("==", time.Date(2024, 7, 4, 21, 27, 12, 0, time.UTC)))
+
+			By("deleting the Alert object right away when resolved alerts are not retained")
+			Expect(notify("secret", "firing")).To(Equal(http.StatusOK))
+			receiver.Reconciler.ResolvedRetention = 0
+			Expect(notify("secret", "resolved")).To(Equal(http.StatusOK))
+			Expect(listAlerts()).To(BeEmpty())
+		})
+
+		It("should update the Alert object of an alert loaded from Prometheus", func() {
+			prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(w, `{"status": "success", "data": {"alerts": [{"activeAt": "2024-07-04T20:27:12Z",
+					"labels": {"alertname": "my-alert", "prometheus_replica": "prometheus-0"}, "state": "firing", "value": "1e+00"}]}}`)
+			}))
+			defer prometheus.Close()
+			receiver.Reconciler.PrometheusBaseURL = prometheus.URL
+			receiver.Reconciler.ReplicaLabels = []string{"prometheus_replica"}
+
+			By("loading the alert from Prometheus")
+			_, err := receiver.Reconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listAlerts()).To(HaveLen(1))
+
+			By("receiving the notification for the alert, with Alertmanager's fingerprint over the external labels")
+			Expect(post("secret", `{"version": "4", "status": "firing", "receiver": "alert-operator",
+				"alerts": [{"status": "firing", "labels": {"alertname": "my-alert", "prometheus_replica": "prometheus-1"},
+				"startsAt": "2024-07-04T20:27:12Z", "fingerprint": "3f2a9c1d5e7b8a60"}]}`)).To(Equal(http.StatusOK))
+			alerts := listAlerts()
+			Expect(alerts).To(HaveLen(1))
+			Expect(alerts[0].Status.Replicas).To(ConsistOf("prometheus_replica=prometheus-0", "prometheus_replica=prometheus-1"))
+
+			By("keeping the alert active on the next sync")
+			_, err = receiver.Reconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			alerts = listAlerts()
+			Expect(alerts).To(HaveLen(1))
+			Expect(alerts[0].Status.State).To(Equal(alertStateFiring))
+		})
+
+		It("should reject unauthorized notifications", func() {
+			Expect(notify("wrong", "firing")).To(Equal(http.StatusUnauthorized))
+			Expect(listAlerts()).To(BeEmpty())
+		})
+	})
+})
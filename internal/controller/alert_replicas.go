@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// ParseReplicaLabels parses a comma-separated list of replica label names, e.g. "prometheus_replica,replica".
+func ParseReplicaLabels(in string) []string {
+	var replicaLabels []string
+	for _, label := range strings.Split(in, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			replicaLabels = append(replicaLabels, label)
+		}
+	}
+	return replicaLabels
+}
+
+// stripReplicaLabels removes the replica labels from the alert and records their values in Replicas.
+// Since the identity of the alert changes, the fingerprint is recomputed from the remaining labels.
+func stripReplicaLabels(a Alert, replicaLabels []string) Alert {
+	var replica []string
+	labels := make(map[string]string, len(a.Labels))
+	for k, v := range a.Labels {
+		if slices.Contains(replicaLabels, k) {
+			replica = append(replica, k+"="+v)
+			continue
+		}
+		labels[k] = v
+	}
+	if len(replica) == 0 {
+		return a
+	}
+
+	slices.Sort(replica)
+	a.Labels = labels
+	a.Fingerprint = alertFingerprint(labels)
+	a.Replicas = []string{strings.Join(replica, ",")}
+	return a
+}
+
+// mergeAlertReplicas removes the replica labels from the alerts and merges the alerts which are identical apart from
+// them, i.e. the same alert reported by several HA replicas, into one. The merged alert lists all replicas and takes
+// its other fields from a firing replica (the first one in the order of the replica label values), so they do not
+// flip between the replicas from one sync to the next. It is active since the earliest replica reported it.
+func mergeAlertReplicas(alerts []Alert, replicaLabels []string) []Alert {
+	if len(replicaLabels) == 0 {
+		return alerts
+	}
+
+	// the alerts the merged alerts are taken from, and the replicas and earliest activeAt of each set of replicas
+	var merged []Alert
+	var replicas [][]string
+	var activeAt []time.Time
+	index := map[string]int{}
+	for _, a := range alerts {
+		a = stripReplicaLabels(a, replicaLabels)
+		i, ok := index[a.Fingerprint]
+		if !ok {
+			index[a.Fingerprint] = len(merged)
+			merged = append(merged, a)
+			replicas = append(replicas, a.Replicas)
+			activeAt = append(activeAt, a.ActiveAt)
+			continue
+		}
+
+		if replicaPrecedes(a, merged[i]) {
+			merged[i] = a
+		}
+		replicas[i] = mergeReplicas(replicas[i], a.Replicas)
+		if !a.ActiveAt.IsZero() && (activeAt[i].IsZero() || a.ActiveAt.Before(activeAt[i])) {
+			activeAt[i] = a.ActiveAt
+		}
+	}
+
+	for i := range merged {
+		merged[i].Replicas = replicas[i]
+		merged[i].ActiveAt = activeAt[i]
+	}
+	return merged
+}
+
+// replicaPrecedes returns true if the merged alert should be taken from a rather than b: firing alerts take
+// precedence over pending ones, otherwise the replica which sorts first is used.
+func replicaPrecedes(a, b Alert) bool {
+	if aFiring, bFiring := alertFiring(a.State), alertFiring(b.State); aFiring != bFiring {
+		return aFiring
+	}
+	return slices.Compare(a.Replicas, b.Replicas) < 0
+}
+
+// mergeReplicas returns the sorted union of both lists of replicas.
+func mergeReplicas(a, b []string) []string {
+	replicas := append(slices.Clone(a), b...)
+	slices.Sort(replicas)
+	return slices.Compact(replicas)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+const (
+	// Reasons for the RuleHealthy condition of Alert objects
+	reasonRuleEvaluationSucceeded = "EvaluationSucceeded"
+	reasonRuleEvaluationFailed    = "EvaluationFailed"
+	reasonRuleHealthUnknown       = "HealthUnknown"
+)
+
+// PrometheusRulesResponse is the response of the Prometheus /api/v1/rules endpoint.
+type PrometheusRulesResponse struct {
+	Data struct {
+		Groups []PrometheusRuleGroup `json:"groups"`
+	} `json:"data"`
+	Status string `json:"status"`
+}
+
+// PrometheusRuleGroup is a rule group returned by the Prometheus /api/v1/rules endpoint.
+type PrometheusRuleGroup struct {
+	Name  string           `json:"name"`
+	File  string           `json:"file"`
+	Rules []PrometheusRule `json:"rules"`
+}
+
+// PrometheusRule is a rule returned by the Prometheus /api/v1/rules endpoint, together with its active alerts.
+type PrometheusRule struct {
+	Type           string            `json:"type"`
+	Name           string            `json:"name"`
+	Query          string            `json:"query"`
+	Duration       float64           `json:"duration"`
+	Labels         map[string]string `json:"labels"`
+	Health         string            `json:"health"`
+	LastError      string            `json:"lastError"`
+	LastEvaluation time.Time         `json:"lastEvaluation"`
+	Alerts         []Alert           `json:"alerts"`
+}
+
+// alertRules looks up the alerting rule which produced an alert.
+type alertRules struct {
+	// byFingerprint contains the rules by the fingerprints of their active alerts.
+	byFingerprint map[string]*alertmanagerprometheusiov1alpha1.AlertRule
+	// byName contains the rules by their name, for alerts which are not (or no longer) listed by their rule.
+	byName map[string][]alertRuleCandidate
+}
+
+type alertRuleCandidate struct {
+	labels map[string]string
+	rule   *alertmanagerprometheusiov1alpha1.AlertRule
+}
+
+// Loads the alerting rules from the /api/v1/rules endpoint of Prometheus (or a compatible API, e.g. Thanos Ruler).
+// The replica labels are removed from the alerts of the rules, like from the alerts themselves.
+func fetchAlertRules(ctx context.Context, httpClient *http.Client, baseURL, bearerToken string,
+	replicaLabels []string) (*alertRules, error) {
+	var rulesResponse PrometheusRulesResponse
+	if err := getPrometheusAPI(ctx, httpClient, baseURL+"/api/v1/rules?type=alert", bearerToken, &rulesResponse); err != nil {
+		return nil, err
+	}
+
+	if rulesResponse.Status != "success" {
+		return nil, fmt.Errorf("Unexpected response status in JSON: '%s'", rulesResponse.Status)
+	}
+
+	return newAlertRules(rulesResponse.Data.Groups, replicaLabels), nil
+}
+
+// newAlertRules indexes the alerting rules of the rule groups.
+func newAlertRules(groups []PrometheusRuleGroup, replicaLabels []string) *alertRules {
+	rules := &alertRules{
+		byFingerprint: map[string]*alertmanagerprometheusiov1alpha1.AlertRule{},
+		byName:        map[string][]alertRuleCandidate{},
+	}
+	for _, group := range groups {
+		for _, r := range group.Rules {
+			if r.Type != "alerting" {
+				continue
+			}
+
+			rule := &alertmanagerprometheusiov1alpha1.AlertRule{
+				Name:           r.Name,
+				Group:          group.Name,
+				File:           group.File,
+				Query:          r.Query,
+				Health:         r.Health,
+				LastError:      r.LastError,
+				LastEvaluation: optionalTime(r.LastEvaluation),
+			}
+			if r.Duration > 0 {
+				rule.For = &metav1.Duration{Duration: time.Duration(r.Duration * float64(time.Second))}
+			}
+
+			for _, a := range r.Alerts {
+				a = stripReplicaLabels(a, replicaLabels)
+				rules.byFingerprint[alertFingerprint(a.Labels)] = rule
+			}
+			rules.byName[r.Name] = append(rules.byName[r.Name], alertRuleCandidate{labels: r.Labels, rule: rule})
+		}
+	}
+	return rules
+}
+
+// lookup returns the alerting rule which produced the alert, or nil if it is not known. Alerts are matched with the
+// alerts listed by the rules. Otherwise, the first rule with the name of the alert whose labels the alert carries is used.
+func (rules *alertRules) lookup(a Alert) *alertmanagerprometheusiov1alpha1.AlertRule {
+	if rule, ok := rules.byFingerprint[alertFingerprint(a.Labels)]; ok {
+		return rule.DeepCopy()
+	}
+
+	for _, candidate := range rules.byName[a.Labels["alertname"]] {
+		matches := true
+		for k, v := range candidate.labels {
+			if a.Labels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return candidate.rule.DeepCopy()
+		}
+	}
+	return nil
+}
+
+// setAlertRule records the alerting rule which produced the alert in the status, and reflects the health of its last
+// evaluation in the RuleHealthy condition. The status is left alone if the rules could not be loaded.
+func setAlertRule(status *alertmanagerprometheusiov1alpha1.AlertStatus, rules *alertRules, a Alert) {
+	if rules == nil {
+		return
+	}
+
+	status.Rule = rules.lookup(a)
+	if status.Rule == nil {
+		meta.RemoveStatusCondition(&status.Conditions, alertmanagerprometheusiov1alpha1.AlertConditionRuleHealthy)
+		return
+	}
+
+	condition := metav1.Condition{Type: alertmanagerprometheusiov1alpha1.AlertConditionRuleHealthy}
+	switch status.Rule.Health {
+	case "ok":
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonRuleEvaluationSucceeded
+		condition.Message = fmt.Sprintf("The rule %s in group %s was evaluated successfully", status.Rule.Name, status.Rule.Group)
+	case "err":
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonRuleEvaluationFailed
+		condition.Message = fmt.Sprintf("The rule %s in group %s failed to evaluate: %s", status.Rule.Name, status.Rule.Group, status.Rule.LastError)
+	default:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = reasonRuleHealthUnknown
+		condition.Message = fmt.Sprintf("The rule %s in group %s has not been evaluated yet", status.Rule.Name, status.Rule.Group)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
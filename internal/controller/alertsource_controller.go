@@ -0,0 +1,291 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+const (
+	// alertSourceFinalizer ensures the alerts of an AlertSource are resolved before the AlertSource object is deleted.
+	alertSourceFinalizer = "alert-operator"
+
+	// defaultAlertSourceInterval is used when AlertSourceSpec.Interval is not set.
+	defaultAlertSourceInterval = 15 * time.Second
+
+	// Reasons for the status conditions of AlertSource objects (in addition to those of Silence objects)
+	reasonSyncFailed         = "SyncFailed"
+	reasonOutsideNamespace   = "OutsideNamespace"
+	reasonSourceNameConflict = "NameConflict"
+)
+
+// AlertSourceReconciler reconciles a AlertSource object.
+// It periodically loads the active alerts from the endpoint of every AlertSource and creates, updates and resolves
+// the Alert objects with the AlertReconciler, which records the AlertSource in status.sources of the Alert objects.
+type AlertSourceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Alerts manages the Alert objects for the alerts loaded from the AlertSources.
+	Alerts *AlertReconciler
+	// Namespace is the namespace of the controller. Only AlertSources in this namespace are polled, since anyone who
+	// can create an AlertSource can inject arbitrary alerts.
+	Namespace string
+	// APIReader reads the Secrets referenced by AlertSources directly from the API server, so they need not be cached.
+	APIReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=alertsources,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=alertsources/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=alertsources/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+
+// Reconcile loads the active alerts from the endpoint of the AlertSource, applies them to the Alert objects and
+// resolves the Alert objects of the source whose alerts are no longer active. It is requeued after the interval of
+// the AlertSource.
+func (r *AlertSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.V(5).Info("Entering AlertSourceController Reconciler", "request", req)
+
+	alertSource := alertmanagerprometheusiov1alpha1.AlertSource{}
+	if err := r.Get(ctx, req.NamespacedName, &alertSource); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if alertSource.GetDeletionTimestamp() != nil {
+		if !controllerutil.ContainsFinalizer(&alertSource, alertSourceFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		// none of the alerts of the source are active anymore
+		if alertSource.Namespace == r.Namespace {
+			if err := r.Alerts.garbageCollectAlerts(ctx, alertSource.Name, nil); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		controllerutil.RemoveFinalizer(&alertSource, alertSourceFinalizer)
+		if err := r.Update(ctx, &alertSource); err != nil {
+			log.Error(err, "Failed to remove finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	status := alertSource.Status.DeepCopy()
+	status.ObservedGeneration = alertSource.Generation
+	setCondition := func(conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               alertmanagerprometheusiov1alpha1.AlertSourceConditionReady,
+			Status:             conditionStatus,
+			ObservedGeneration: alertSource.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	// the spec needs to be fixed by the user (or the object moved), retrying won't help
+	if alertSource.Namespace != r.Namespace {
+		setCondition(metav1.ConditionFalse, reasonOutsideNamespace,
+			fmt.Sprintf("Only AlertSources in the namespace '%s' are loaded", r.Namespace))
+		return ctrl.Result{}, r.updateAlertSourceStatus(ctx, &alertSource, status)
+	}
+	if r.Alerts.AlertSource != AlertSourceNone && alertSource.Name == r.Alerts.BuiltinSource() {
+		setCondition(metav1.ConditionFalse, reasonSourceNameConflict,
+			fmt.Sprintf("The name '%s' is used by the alert source configured on the command line", alertSource.Name))
+		return ctrl.Result{}, r.updateAlertSourceStatus(ctx, &alertSource, status)
+	}
+
+	if !controllerutil.ContainsFinalizer(&alertSource, alertSourceFinalizer) {
+		controllerutil.AddFinalizer(&alertSource, alertSourceFinalizer)
+		if err := r.Update(ctx, &alertSource); err != nil {
+			log.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	interval := defaultAlertSourceInterval
+	if alertSource.Spec.Interval != nil && alertSource.Spec.Interval.Duration > 0 {
+		interval = alertSource.Spec.Interval.Duration
+	}
+
+	selector := labels.Everything()
+	if alertSource.Spec.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(alertSource.Spec.Selector); err != nil {
+			setCondition(metav1.ConditionFalse, reasonInvalidSpec, fmt.Sprintf("Invalid selector: %s", err))
+			return ctrl.Result{}, r.updateAlertSourceStatus(ctx, &alertSource, status)
+		}
+	}
+
+	alerts, rules, err := r.fetchAlerts(ctx, &alertSource)
+	if err != nil {
+		// keep the Alert objects of the source as they are until the endpoint is reachable again
+		log.Error(err, "Failed to load alerts from AlertSource", "name", alertSource.Name)
+		setCondition(metav1.ConditionFalse, reasonSyncFailed, err.Error())
+		if err := r.updateAlertSourceStatus(ctx, &alertSource, status); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	var selected []Alert
+	for _, a := range alerts {
+		if selector.Matches(labels.Set(a.Labels)) {
+			selected = append(selected, a)
+		}
+	}
+	selected = mergeAlertReplicas(selected, r.Alerts.ReplicaLabels)
+
+	activeAlerts := map[types.NamespacedName]bool{}
+	for _, a := range selected {
+		key, err := r.Alerts.upsertAlert(ctx, alertSource.Name, a, func(status *alertmanagerprometheusiov1alpha1.AlertStatus) {
+			status.State = a.State
+			status.Annotations = a.Annotations
+			status.Labels = a.Labels
+			status.ActiveAt = optionalTime(a.ActiveAt)
+			status.Since = ""
+			status.Value = a.Value
+			status.Replicas = a.Replicas
+			setAlertRule(status, rules, a)
+		})
+		activeAlerts[key] = true
+		if err != nil {
+			log.Error(err, "Unable to create or update Alert", "name", key.Name, "namespace", key.Namespace)
+		}
+	}
+	if err := r.Alerts.garbageCollectAlerts(ctx, alertSource.Name, activeAlerts); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	status.Alerts = int32(len(selected))
+	setCondition(metav1.ConditionTrue, reasonSynced, fmt.Sprintf("Loaded %d alerts from %s", len(selected), alertSource.Spec.URL))
+	if err := r.updateAlertSourceStatus(ctx, &alertSource, status); err != nil {
+		log.Error(err, "Failed to update AlertSource status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// fetchAlerts loads the active alerts (and, if enabled, the alerting rules) from the endpoint of the AlertSource, with
+// the credentials and TLS settings referenced by the AlertSource. If the rules cannot be loaded, they are left out.
+func (r *AlertSourceReconciler) fetchAlerts(ctx context.Context,
+	alertSource *alertmanagerprometheusiov1alpha1.AlertSource) ([]Alert, *alertRules, error) {
+	var bearerToken string
+	if ref := alertSource.Spec.BearerTokenSecret; ref != nil {
+		token, err := r.secretValue(ctx, alertSource.Namespace, ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		bearerToken = strings.TrimSpace(string(token))
+	}
+
+	tlsConfig := &tls.Config{}
+	if spec := alertSource.Spec.TLS; spec != nil {
+		tlsConfig.InsecureSkipVerify = spec.InsecureSkipVerify
+		// if necessary, trust the CA bundle in addition to the system roots
+		if spec.CASecret != nil {
+			caCert, err := r.secretValue(ctx, alertSource.Namespace, spec.CASecret)
+			if err != nil {
+				return nil, nil, err
+			}
+			caPool, err := x509.SystemCertPool()
+			if err != nil {
+				caPool = x509.NewCertPool()
+			}
+			if !caPool.AppendCertsFromPEM(caCert) {
+				return nil, nil, fmt.Errorf("No valid PEM certificates found in key '%s' of Secret '%s'", spec.CASecret.Key, spec.CASecret.Name)
+			}
+			tlsConfig.RootCAs = caPool
+		}
+	}
+
+	tr := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+	defer tr.CloseIdleConnections()
+	httpClient := &http.Client{Transport: tr, Timeout: 30 * time.Second}
+
+	baseURL := strings.TrimSuffix(alertSource.Spec.URL, "/")
+	alerts, err := fetchPrometheusAlerts(ctx, httpClient, baseURL, bearerToken)
+	if err != nil || !r.Alerts.LoadRules {
+		return alerts, nil, err
+	}
+	rules, err := fetchAlertRules(ctx, httpClient, baseURL, bearerToken, r.Alerts.ReplicaLabels)
+	if err != nil {
+		// the alerts are still useful without their rules
+		log.FromContext(ctx).Error(err, "Unable to load alerting rules from AlertSource", "name", alertSource.Name)
+	}
+	return alerts, rules, nil
+}
+
+// secretValue returns the value of the Secret key selected by ref.
+func (r *AlertSourceReconciler) secretValue(ctx context.Context, namespace string, ref *corev1.SecretKeySelector) ([]byte, error) {
+	secret := corev1.Secret{}
+	if err := r.APIReader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("Failed to get Secret '%s': %w", ref.Name, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("Secret '%s' has no key '%s'", ref.Name, ref.Key)
+	}
+	return value, nil
+}
+
+// updateAlertSourceStatus writes the status of the AlertSource if it has changed.
+func (r *AlertSourceReconciler) updateAlertSourceStatus(ctx context.Context,
+	alertSource *alertmanagerprometheusiov1alpha1.AlertSource, status *alertmanagerprometheusiov1alpha1.AlertSourceStatus) error {
+	if apiequality.Semantic.DeepEqual(alertSource.Status, *status) {
+		return nil
+	}
+	alertSource.Status = *status
+	return r.Status().Update(ctx, alertSource)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AlertSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// status updates must not trigger a sync, the AlertSources are polled with RequeueAfter
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&alertmanagerprometheusiov1alpha1.AlertSource{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+var _ = Describe("AlertSource Controller", func() {
+	Context("When loading alerts from several AlertSources", func() {
+		ctx := context.Background()
+
+		var platformAlerts, userAlerts string
+		var platform, user *httptest.Server
+		var controllerReconciler *AlertSourceReconciler
+
+		newPrometheus := func(alerts *string) *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("Authorization") != "Bearer secret" {
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprintf(w, `{"status": "success", "data": {"alerts": %s}}`, *alerts)
+			}))
+		}
+
+		BeforeEach(func() {
+			platformAlerts = `[
+				{"labels": {"alertname": "my-alert", "severity": "critical"}, "state": "firing", "value": "1e+00"},
+				{"labels": {"alertname": "other-alert", "severity": "info"}, "state": "firing"}
+			]`
+			userAlerts = `[{"labels": {"alertname": "my-alert", "severity": "critical"}, "state": "firing", "value": "2e+00"}]`
+			platform = newPrometheus(&platformAlerts)
+			user = newPrometheus(&userAlerts)
+
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus-token", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("secret\n")},
+			})).To(Succeed())
+			token := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "prometheus-token"}, Key: "token"}
+			Expect(k8sClient.Create(ctx, &alertmanagerprometheusiov1alpha1.AlertSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "platform", Namespace: "default"},
+				Spec: alertmanagerprometheusiov1alpha1.AlertSourceSpec{
+					URL:               platform.URL,
+					BearerTokenSecret: token,
+					Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "severity", Operator: metav1.LabelSelectorOpIn, Values: []string{"warning", "critical"}},
+					}},
+				},
+			})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &alertmanagerprometheusiov1alpha1.AlertSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "user-workload", Namespace: "default"},
+				Spec: alertmanagerprometheusiov1alpha1.AlertSourceSpec{
+					URL:               user.URL,
+					BearerTokenSecret: token,
+					Interval:          &metav1.Duration{Duration: time.Minute},
+				},
+			})).To(Succeed())
+
+			controllerReconciler = &AlertSourceReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Alerts: &AlertReconciler{
+					Client:              k8sClient,
+					Scheme:              k8sClient.Scheme(),
+					ControllerNamespace: "default",
+					AlertSource:         AlertSourceNone,
+					ResolvedRetention:   time.Hour,
+				},
+				Namespace: "default",
+				APIReader: k8sClient,
+			}
+		})
+
+		AfterEach(func() {
+			platform.Close()
+			user.Close()
+			for _, name := range []string{"platform", "user-workload"} {
+				alertSource := &alertmanagerprometheusiov1alpha1.AlertSource{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, alertSource); err == nil {
+					alertSource.Finalizers = nil
+					Expect(k8sClient.Update(ctx, alertSource)).To(Succeed())
+					Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, alertSource))).To(Succeed())
+				}
+			}
+			Expect(k8sClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "prometheus-token", Namespace: "default"}})).To(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &alertmanagerprometheusiov1alpha1.Alert{},
+				client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+		})
+
+		reconcileSource := func(name string) reconcile.Result {
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+
+		listAlerts := func() []alertmanagerprometheusiov1alpha1.Alert {
+			alerts := &alertmanagerprometheusiov1alpha1.AlertList{}
+			Expect(k8sClient.List(ctx, alerts, client.InNamespace("default"), client.MatchingLabels{managedByLabel: managedByValue})).To(Succeed())
+			return alerts.Items
+		}
+
+		It("should merge the alerts of all sources into one Alert object per alert", func() {
+			By("loading the alerts matching the selector from the first source")
+			Expect(reconcileSource("platform").RequeueAfter).To(Equal(defaultAlertSourceInterval))
+			alerts := listAlerts()
+			Expect(alerts).To(HaveLen(1))
+			Expect(alerts[0].Status.Labels).To(HaveKeyWithValue("alertname", "my-alert"))
+			Expect(alerts[0].Status.Sources).To(Equal([]string{"platform"}))
+			Expect(alerts[0].Labels).To(HaveKeyWithValue(sourceLabel, "platform"))
+
+			alertSource := &alertmanagerprometheusiov1alpha1.AlertSource{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "platform", Namespace: "default"}, alertSource)).To(Succeed())
+			Expect(alertSource.Finalizers).To(ContainElement(alertSourceFinalizer))
+			Expect(alertSource.Status.Alerts).To(BeEquivalentTo(1))
+			Expect(alertSource.Status.LastSyncTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(alertSource.Status.Conditions, alertmanagerprometheusiov1alpha1.AlertSourceConditionReady)).To(BeTrue())
+
+			By("adding the second source to the existing Alert object, keeping the status of the first")
+			Expect(reconcileSource("user-workload").RequeueAfter).To(Equal(time.Minute))
+			alerts = listAlerts()
+			Expect(alerts).To(HaveLen(1))
+			Expect(alerts[0].Status.Sources).To(Equal([]string{"platform", "user-workload"}))
+			Expect(alerts[0].Status.Value).To(Equal("1e+00"))
+
+			By("handing the Alert object over to the remaining source")
+			platformAlerts = `[]`
+			reconcileSource("platform")
+			alerts = listAlerts()
+			Expect(alerts).To(HaveLen(1))
+			Expect(alerts[0].Status.Sources).To(Equal([]string{"user-workload"}))
+			Expect(alerts[0].Status.ResolvedAt).To(BeNil())
+			Expect(alerts[0].Labels).To(HaveKeyWithValue(sourceLabel, "user-workload"))
+			reconcileSource("user-workload")
+			Expect(listAlerts()[0].Status.Value).To(Equal("2e+00"))
+
+			By("resolving the alerts of a source when the AlertSource is deleted")
+			Expect(k8sClient.Delete(ctx, &alertmanagerprometheusiov1alpha1.AlertSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "user-workload", Namespace: "default"},
+			})).To(Succeed())
+			reconcileSource("user-workload")
+			alerts = listAlerts()
+			Expect(alerts).To(HaveLen(1))
+			Expect(alerts[0].Status.State).To(Equal(alertStateResolved))
+			Expect(alerts[0].Status.Sources).To(Equal([]string{"user-workload"}))
+		})
+
+		It("should report endpoints which cannot be loaded", func() {
+			alertSource := &alertmanagerprometheusiov1alpha1.AlertSource{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "platform", Namespace: "default"}, alertSource)).To(Succeed())
+			alertSource.Spec.BearerTokenSecret.Key = "does-not-exist"
+			Expect(k8sClient.Update(ctx, alertSource)).To(Succeed())
+
+			Expect(reconcileSource("platform").RequeueAfter).To(Equal(defaultAlertSourceInterval))
+			Expect(listAlerts()).To(BeEmpty())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "platform", Namespace: "default"}, alertSource)).To(Succeed())
+			condition := meta.FindStatusCondition(alertSource.Status.Conditions, alertmanagerprometheusiov1alpha1.AlertSourceConditionReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(reasonSyncFailed))
+		})
+	})
+})
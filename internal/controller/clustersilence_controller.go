@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+const (
+	// clusterSilenceLabel contains the name of the ClusterSilence which a Silence object belongs to.
+	clusterSilenceLabel = "alertmanager.prometheus.io/clustersilence"
+
+	reasonNameConflict = "NameConflict"
+)
+
+// ClusterSilenceReconciler reconciles a ClusterSilence object.
+// Every ClusterSilence is backed by a Silence object in the namespace of the operator, which takes care of the
+// silence in Alertmanager. The status of that Silence object is reported on the ClusterSilence.
+type ClusterSilenceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Namespace is the namespace of the operator, in which the Silence objects are created.
+	Namespace string
+}
+
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=clustersilences,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=clustersilences/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=clustersilences/finalizers,verbs=update
+
+// Reconcile creates or updates the Silence object of a ClusterSilence and copies its status.
+// The Silence object is garbage collected together with the ClusterSilence, its finalizer expires the silence in Alertmanager.
+func (r *ClusterSilenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.V(5).Info("Entering ClusterSilenceController Reconciler", "request", req)
+
+	clusterSilence := alertmanagerprometheusiov1alpha1.ClusterSilence{}
+	if err := r.Get(ctx, req.NamespacedName, &clusterSilence); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if clusterSilence.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	silence := alertmanagerprometheusiov1alpha1.Silence{}
+	silence.Name = clusterSilenceChildName(clusterSilence.Name)
+	silence.Namespace = r.Namespace
+
+	// never take over a Silence object which was created by someone else
+	if err := r.Get(ctx, client.ObjectKeyFromObject(&silence), &silence); err == nil && !metav1.IsControlledBy(&silence, &clusterSilence) {
+		meta.SetStatusCondition(&clusterSilence.Status.Conditions, metav1.Condition{
+			Type:               alertmanagerprometheusiov1alpha1.SilenceConditionReady,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: clusterSilence.Generation,
+			Reason:             reasonNameConflict,
+			Message:            fmt.Sprintf("The Silence %s/%s already exists and does not belong to this ClusterSilence", silence.Namespace, silence.Name),
+		})
+		return ctrl.Result{}, r.Status().Update(ctx, &clusterSilence)
+	} else if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, &silence, func() error {
+		silence.Spec = *clusterSilence.Spec.DeepCopy()
+		// a relative duration must be counted from the creation of the ClusterSilence, not from the Silence object
+		if silence.Spec.StartsAt.IsZero() {
+			silence.Spec.StartsAt = clusterSilence.CreationTimestamp
+		}
+		setLabel(&silence, clusterSilenceLabel, clusterSilenceLabelValue(clusterSilence.Name))
+		setLabel(&silence, silenceOwnerLabel, silenceOwnerKubernetes)
+		return controllerutil.SetControllerReference(&clusterSilence, &silence, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to create or update Silence for ClusterSilence", "name", clusterSilence.Name)
+		return ctrl.Result{}, err
+	}
+
+	status := *silence.Status.DeepCopy()
+	status.ObservedGeneration = clusterSilence.Status.ObservedGeneration
+	if silence.Status.ObservedGeneration == silence.Generation {
+		status.ObservedGeneration = clusterSilence.Generation
+	}
+	for i := range status.Conditions {
+		status.Conditions[i].ObservedGeneration = status.ObservedGeneration
+	}
+	if apiequality.Semantic.DeepEqual(status, clusterSilence.Status) {
+		return ctrl.Result{}, nil
+	}
+	clusterSilence.Status = status
+	if err := r.Status().Update(ctx, &clusterSilence); err != nil {
+		log.Error(err, "Failed to update ClusterSilence status", "name", clusterSilence.Name)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// clusterSilenceChildName returns the name of the Silence object which backs the ClusterSilence.
+func clusterSilenceChildName(name string) string {
+	const prefix = "clustersilence-"
+	return prefix + truncateName(name, validation.DNS1123SubdomainMaxLength-len(prefix))
+}
+
+// clusterSilenceLabelValue returns the value of the label which links the Silence object to its ClusterSilence.
+func clusterSilenceLabelValue(name string) string {
+	return truncateName(name, validation.LabelValueMaxLength)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterSilenceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&alertmanagerprometheusiov1alpha1.ClusterSilence{}).
+		// the status is copied from the Silence object
+		Owns(&alertmanagerprometheusiov1alpha1.Silence{}).
+		Complete(r)
+}
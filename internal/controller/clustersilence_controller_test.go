@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+var _ = Describe("ClusterSilence Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "cluster-maintenance"
+
+		ctx := context.Background()
+
+		clusterSilenceKey := types.NamespacedName{Name: resourceName}
+		silenceKey := types.NamespacedName{Name: "clustersilence-" + resourceName, Namespace: "default"}
+		var controllerReconciler *ClusterSilenceReconciler
+
+		BeforeEach(func() {
+			controllerReconciler = &ClusterSilenceReconciler{
+				Client:    k8sClient,
+				Scheme:    k8sClient.Scheme(),
+				Namespace: "default",
+			}
+
+			clusterSilence := &alertmanagerprometheusiov1alpha1.ClusterSilence{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					MatchLabels: map[string]string{"alertname": "KubeNodeNotReady"},
+					EndsAt:      metav1.NewTime(time.Now().Add(time.Hour)),
+					CreatedBy:   "platform-admin",
+					Comment:     "rolling reboot",
+				},
+			}
+			Expect(k8sClient.Create(ctx, clusterSilence)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, obj := range []client.Object{
+				&alertmanagerprometheusiov1alpha1.Silence{ObjectMeta: metav1.ObjectMeta{Name: silenceKey.Name, Namespace: silenceKey.Namespace}},
+				&alertmanagerprometheusiov1alpha1.ClusterSilence{ObjectMeta: metav1.ObjectMeta{Name: resourceName}},
+			} {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, obj))).To(Succeed())
+			}
+		})
+
+		It("should create a Silence object in the controller namespace", func() {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: clusterSilenceKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterSilence := &alertmanagerprometheusiov1alpha1.ClusterSilence{}
+			Expect(k8sClient.Get(ctx, clusterSilenceKey, clusterSilence)).To(Succeed())
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			Expect(k8sClient.Get(ctx, silenceKey, silence)).To(Succeed())
+			Expect(metav1.IsControlledBy(silence, clusterSilence)).To(BeTrue())
+			Expect(silence.Labels).To(HaveKeyWithValue(clusterSilenceLabel, resourceName))
+			Expect(silence.Spec.MatchLabels).To(Equal(clusterSilence.Spec.MatchLabels))
+			Expect(silence.Spec.CreatedBy).To(Equal("platform-admin"))
+
+			By("reporting the status of the Silence object")
+			silence.Status.SilenceId = "silence-42"
+			silence.Status.State = alertmanagerprometheusiov1alpha1.SilenceStateActive
+			Expect(k8sClient.Status().Update(ctx, silence)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: clusterSilenceKey})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, clusterSilenceKey, clusterSilence)).To(Succeed())
+			Expect(clusterSilence.Status.SilenceId).To(Equal("silence-42"))
+			Expect(clusterSilence.Status.State).To(Equal(alertmanagerprometheusiov1alpha1.SilenceStateActive))
+
+			By("reverting changes to the Silence object")
+			silence.Spec.Comment = "edited"
+			Expect(k8sClient.Update(ctx, silence)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: clusterSilenceKey})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, silenceKey, silence)).To(Succeed())
+			Expect(silence.Spec.Comment).To(Equal("rolling reboot"))
+		})
+
+		It("should not take over an existing Silence object", func() {
+			silence := &alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{Name: silenceKey.Name, Namespace: silenceKey.Namespace},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					MatchLabels: map[string]string{"alertname": "Watchdog"},
+					EndsAt:      metav1.NewTime(time.Now().Add(time.Hour)),
+				},
+			}
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: clusterSilenceKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, silenceKey, silence)).To(Succeed())
+			Expect(silence.Spec.MatchLabels).To(HaveKeyWithValue("alertname", "Watchdog"))
+			clusterSilence := &alertmanagerprometheusiov1alpha1.ClusterSilence{}
+			Expect(k8sClient.Get(ctx, clusterSilenceKey, clusterSilence)).To(Succeed())
+			Expect(meta.FindStatusCondition(clusterSilence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionReady).Reason).
+				To(Equal(reasonNameConflict))
+		})
+	})
+
+	Context("When naming the Silence object of a ClusterSilence", func() {
+		It("should keep the names of long ClusterSilences valid and unique", func() {
+			longName := strings.Repeat("maintenance.", 20) + "cluster-a"
+			otherName := strings.Repeat("maintenance.", 20) + "cluster-b"
+			Expect(longName).To(HaveLen(249))
+
+			name := clusterSilenceChildName(longName)
+			Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+			Expect(name).To(HavePrefix("clustersilence-maintenance."))
+			Expect(name).NotTo(Equal(clusterSilenceChildName(otherName)))
+			Expect(clusterSilenceChildName("short")).To(Equal("clustersilence-short"))
+
+			labelValue := clusterSilenceLabelValue(longName)
+			Expect(validation.IsValidLabelValue(labelValue)).To(BeEmpty())
+			Expect(labelValue).NotTo(Equal(clusterSilenceLabelValue(otherName)))
+		})
+	})
+})
@@ -0,0 +1,333 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+const (
+	// recurringSilenceLabel contains the name of the RecurringSilence which a Silence object was created for.
+	recurringSilenceLabel = "alertmanager.prometheus.io/recurringsilence"
+
+	// defaultCreateBefore is used when RecurringSilenceSpec.CreateBefore is not set.
+	defaultCreateBefore = time.Hour
+	// maxRecurringSilenceWindows limits how many windows of a schedule are materialized at the same time,
+	// e.g. for schedules whose windows overlap.
+	maxRecurringSilenceWindows = 10
+	// maxScheduleLookback limits how far back the previous window of a schedule is searched.
+	maxScheduleLookback = 366 * 24 * time.Hour
+
+	reasonScheduled       = "Scheduled"
+	reasonSuspended       = "Suspended"
+	reasonInvalidSchedule = "InvalidSchedule"
+)
+
+// RecurringSilenceReconciler reconciles a RecurringSilence object.
+// Shortly before every window of the schedule it creates a Silence object for the window, which takes care of
+// the silence in Alertmanager. The Silence object is deleted again once the window has ended.
+type RecurringSilenceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=recurringsilences,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=recurringsilences/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=recurringsilences/finalizers,verbs=update
+
+// Reconcile creates the Silence objects for the current and upcoming windows of the schedule,
+// removes those of past windows and reports the previous and next window in the status.
+func (r *RecurringSilenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.V(5).Info("Entering RecurringSilenceController Reconciler", "request", req)
+
+	recurringSilence := alertmanagerprometheusiov1alpha1.RecurringSilence{}
+	if err := r.Get(ctx, req.NamespacedName, &recurringSilence); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if recurringSilence.GetDeletionTimestamp() != nil {
+		// the Silence objects are garbage collected, their finalizers expire the silences in Alertmanager
+		return ctrl.Result{}, nil
+	}
+
+	status := recurringSilence.Status.DeepCopy()
+	status.ObservedGeneration = recurringSilence.Generation
+	setCondition := func(conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               alertmanagerprometheusiov1alpha1.SilenceConditionReady,
+			Status:             conditionStatus,
+			ObservedGeneration: recurringSilence.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	schedule, err := parseRecurringSchedule(recurringSilence.Spec)
+	if err != nil {
+		// the spec needs to be fixed by the user, retrying won't help
+		log.Error(err, "Invalid schedule in RecurringSilence", "name", recurringSilence.Name, "namespace", recurringSilence.Namespace)
+		status.NextWindow = nil
+		setCondition(metav1.ConditionFalse, reasonInvalidSchedule, err.Error())
+		return ctrl.Result{}, r.updateRecurringSilenceStatus(ctx, &recurringSilence, status)
+	}
+	if err := validateMatchers(alertmanagerprometheusiov1alpha1.SilenceSpec{
+		MatchLabels: recurringSilence.Spec.MatchLabels,
+		Matchers:    recurringSilence.Spec.Matchers,
+	}); err != nil {
+		log.Error(err, "Invalid matchers in RecurringSilence", "name", recurringSilence.Name, "namespace", recurringSilence.Namespace)
+		setCondition(metav1.ConditionFalse, reasonInvalidSpec, err.Error())
+		return ctrl.Result{}, r.updateRecurringSilenceStatus(ctx, &recurringSilence, status)
+	}
+
+	now := time.Now()
+	duration := recurringSilence.Spec.Duration.Duration
+	createBefore := defaultCreateBefore
+	if recurringSilence.Spec.CreateBefore != nil {
+		createBefore = recurringSilence.Spec.CreateBefore.Duration
+	}
+
+	// all windows which have not ended yet and start soon enough need a Silence object
+	var windows []time.Time
+	nextCreation := time.Time{}
+	for startsAt := schedule.Next(now.Add(-duration)); !startsAt.IsZero(); startsAt = schedule.Next(startsAt) {
+		if startsAt.After(now.Add(createBefore)) || len(windows) == maxRecurringSilenceWindows {
+			nextCreation = startsAt.Add(-createBefore)
+			break
+		}
+		windows = append(windows, startsAt)
+	}
+	if recurringSilence.Spec.Suspend {
+		windows = nil
+	}
+
+	existing := alertmanagerprometheusiov1alpha1.SilenceList{}
+	if err := r.List(ctx, &existing, client.InNamespace(recurringSilence.Namespace),
+		client.MatchingLabels{recurringSilenceLabel: recurringSilenceLabelValue(recurringSilence.Name)}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	desired := map[string]bool{}
+	for _, startsAt := range windows {
+		silence := alertmanagerprometheusiov1alpha1.Silence{}
+		silence.Name = recurringSilenceChildName(recurringSilence.Name, startsAt)
+		silence.Namespace = recurringSilence.Namespace
+		desired[silence.Name] = true
+
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, &silence, func() error {
+			silence.Spec = alertmanagerprometheusiov1alpha1.SilenceSpec{
+				MatchLabels: recurringSilence.Spec.MatchLabels,
+				Matchers:    recurringSilence.Spec.Matchers,
+				StartsAt:    metav1.NewTime(startsAt),
+				EndsAt:      metav1.NewTime(startsAt.Add(duration)),
+				CreatedBy:   recurringSilence.Spec.CreatedBy,
+				Comment:     recurringSilence.Spec.Comment,
+			}
+			// the creator is filled in by the webhook, unless it is disabled
+			if silence.Spec.CreatedBy == "" {
+				silence.Spec.CreatedBy = fmt.Sprintf("RecurringSilence %s/%s", recurringSilence.Namespace, recurringSilence.Name)
+			}
+			setLabel(&silence, recurringSilenceLabel, recurringSilenceLabelValue(recurringSilence.Name))
+			return controllerutil.SetControllerReference(&recurringSilence, &silence, r.Scheme)
+		})
+		if apierrors.IsInvalid(err) {
+			// the Silence webhook rejected the window, e.g. because it is longer than the maximum duration
+			log.Error(err, "Silence for window was rejected", "name", silence.Name, "namespace", silence.Namespace)
+			setCondition(metav1.ConditionFalse, reasonInvalidSpec, err.Error())
+			return ctrl.Result{}, r.updateRecurringSilenceStatus(ctx, &recurringSilence, status)
+		}
+		if err != nil {
+			log.Error(err, "Failed to create or update Silence for window", "name", silence.Name, "namespace", silence.Namespace)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Silence objects of past windows (or windows which are no longer part of the schedule) are removed.
+	// Those of a suspended schedule are kept until their window has ended.
+	status.Silences = nil
+	for _, silence := range existing.Items {
+		if !metav1.IsControlledBy(&silence, &recurringSilence) || desired[silence.Name] {
+			continue
+		}
+		if recurringSilence.Spec.Suspend && silence.Spec.EndsAt.After(now) {
+			desired[silence.Name] = true
+			continue
+		}
+		if err := r.Delete(ctx, &silence); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to delete Silence of past window", "name", silence.Name, "namespace", silence.Namespace)
+			return ctrl.Result{}, err
+		}
+		log.V(5).Info("Deleted Silence of past window", "name", silence.Name, "namespace", silence.Namespace)
+	}
+	for name := range desired {
+		status.Silences = append(status.Silences, name)
+	}
+	slices.Sort(status.Silences)
+
+	status.PreviousWindow = nil
+	if startsAt := previousScheduleTime(schedule, now); !startsAt.IsZero() {
+		status.PreviousWindow = &alertmanagerprometheusiov1alpha1.SilenceWindow{
+			StartsAt: metav1.NewTime(startsAt),
+			EndsAt:   metav1.NewTime(startsAt.Add(duration)),
+		}
+	}
+	status.NextWindow = nil
+	if startsAt := schedule.Next(now); !startsAt.IsZero() && !recurringSilence.Spec.Suspend {
+		status.NextWindow = &alertmanagerprometheusiov1alpha1.SilenceWindow{
+			StartsAt: metav1.NewTime(startsAt),
+			EndsAt:   metav1.NewTime(startsAt.Add(duration)),
+		}
+	}
+
+	switch {
+	case recurringSilence.Spec.Suspend:
+		setCondition(metav1.ConditionFalse, reasonSuspended, "No silences are created for future windows")
+	case status.NextWindow != nil:
+		setCondition(metav1.ConditionTrue, reasonScheduled,
+			fmt.Sprintf("The next window starts at %s", status.NextWindow.StartsAt.Format(time.RFC3339)))
+	default:
+		setCondition(metav1.ConditionFalse, reasonScheduled, "The schedule has no upcoming windows")
+	}
+
+	if err := r.updateRecurringSilenceStatus(ctx, &recurringSilence, status); err != nil {
+		log.Error(err, "Failed to update RecurringSilence status")
+		return ctrl.Result{}, err
+	}
+
+	// come back when the next Silence object needs to be created or the current window ends
+	var wakeups []time.Time
+	if !nextCreation.IsZero() && !recurringSilence.Spec.Suspend {
+		wakeups = append(wakeups, nextCreation)
+	}
+	for _, startsAt := range windows {
+		wakeups = append(wakeups, startsAt.Add(duration))
+	}
+	if len(wakeups) == 0 {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: max(time.Until(slices.MinFunc(wakeups, time.Time.Compare)), 0) + time.Second}, nil
+}
+
+// updateRecurringSilenceStatus writes the status of the RecurringSilence if it has changed.
+func (r *RecurringSilenceReconciler) updateRecurringSilenceStatus(ctx context.Context,
+	recurringSilence *alertmanagerprometheusiov1alpha1.RecurringSilence, status *alertmanagerprometheusiov1alpha1.RecurringSilenceStatus) error {
+	if apiequality.Semantic.DeepEqual(recurringSilence.Status, *status) {
+		return nil
+	}
+	recurringSilence.Status = *status
+	return r.Status().Update(ctx, recurringSilence)
+}
+
+// parseRecurringSchedule parses the cron schedule of the RecurringSilence in its time zone.
+func parseRecurringSchedule(spec alertmanagerprometheusiov1alpha1.RecurringSilenceSpec) (cron.Schedule, error) {
+	if spec.Duration.Duration <= 0 {
+		return nil, fmt.Errorf("Invalid duration '%s', it must be positive", spec.Duration.Duration)
+	}
+	location := time.UTC
+	if spec.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("Invalid time zone '%s': %w", spec.TimeZone, err)
+		}
+	}
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid schedule '%s': %w", spec.Schedule, err)
+	}
+	// the schedule is evaluated in the location of the time it is given
+	return inLocation{schedule, location}, nil
+}
+
+// inLocation evaluates a cron schedule in the given time zone.
+type inLocation struct {
+	cron.Schedule
+	location *time.Location
+}
+
+func (s inLocation) Next(t time.Time) time.Time {
+	return s.Schedule.Next(t.In(s.location))
+}
+
+// previousScheduleTime returns the latest time of the schedule before or at the given time, or the zero time if
+// there is none within maxScheduleLookback. Cron schedules can only be evaluated forwards, so the search starts
+// with a short lookback which is extended until a time is found.
+func previousScheduleTime(schedule cron.Schedule, t time.Time) time.Time {
+	for lookback := time.Hour; ; lookback = min(4*lookback, maxScheduleLookback) {
+		previous := time.Time{}
+		for next := schedule.Next(t.Add(-lookback)); !next.IsZero() && !next.After(t); next = schedule.Next(next) {
+			previous = next
+		}
+		if !previous.IsZero() || lookback == maxScheduleLookback {
+			return previous
+		}
+	}
+}
+
+// recurringSilenceChildName returns the name of the Silence object for the window starting at the given time.
+// Like the Jobs of a CronJob, it is suffixed with the scheduled time in minutes.
+func recurringSilenceChildName(name string, startsAt time.Time) string {
+	suffix := fmt.Sprintf("-%d", startsAt.Unix()/60)
+	return truncateName(name, validation.DNS1123SubdomainMaxLength-len(suffix)) + suffix
+}
+
+// recurringSilenceLabelValue returns the value of the label which links the Silence objects to their RecurringSilence.
+func recurringSilenceLabelValue(name string) string {
+	return truncateName(name, validation.LabelValueMaxLength)
+}
+
+// truncateName shortens a name to at most maxLength characters. Names which are too long are truncated and made
+// unique again with a hash of the full name.
+func truncateName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	hash := fmt.Sprintf("-%08x", h.Sum32())
+	return strings.TrimRight(name[:maxLength-len(hash)], "-.") + hash
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RecurringSilenceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&alertmanagerprometheusiov1alpha1.RecurringSilence{}).
+		// recreate Silence objects which have been deleted before their window ended
+		Owns(&alertmanagerprometheusiov1alpha1.Silence{}).
+		Complete(r)
+}
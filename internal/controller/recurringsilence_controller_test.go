@@ -0,0 +1,226 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+)
+
+var _ = Describe("RecurringSilence Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "weekly-patching"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		var controllerReconciler *RecurringSilenceReconciler
+		var recurringSilence *alertmanagerprometheusiov1alpha1.RecurringSilence
+
+		BeforeEach(func() {
+			controllerReconciler = &RecurringSilenceReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			recurringSilence = &alertmanagerprometheusiov1alpha1.RecurringSilence{
+				ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+				Spec: alertmanagerprometheusiov1alpha1.RecurringSilenceSpec{
+					Schedule:    "0 * * * *",
+					Duration:    metav1.Duration{Duration: 30 * time.Minute},
+					MatchLabels: map[string]string{"alertname": "KubeNodeNotReady"},
+					Comment:     "patching",
+				},
+			}
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, recurringSilence))).To(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &alertmanagerprometheusiov1alpha1.Silence{}, client.InNamespace("default"),
+				client.HasLabels{recurringSilenceLabel})).To(Succeed())
+		})
+
+		reconcileRecurringSilence := func() {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, recurringSilence)).To(Succeed())
+		}
+
+		It("should create a Silence object ahead of the next window", func() {
+			Expect(k8sClient.Create(ctx, recurringSilence)).To(Succeed())
+			reconcileRecurringSilence()
+
+			nextWindow := time.Now().Truncate(time.Hour).Add(time.Hour)
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      recurringSilenceChildName(resourceName, nextWindow),
+				Namespace: "default",
+			}, silence)).To(Succeed())
+			Expect(metav1.IsControlledBy(silence, recurringSilence)).To(BeTrue())
+			Expect(silence.Spec.StartsAt.Time).To(BeTemporally("==", nextWindow))
+			Expect(silence.Spec.EndsAt.Time).To(BeTemporally("==", nextWindow.Add(30*time.Minute)))
+			Expect(silence.Spec.MatchLabels).To(HaveKeyWithValue("alertname", "KubeNodeNotReady"))
+			Expect(silence.Spec.CreatedBy).To(Equal("RecurringSilence default/" + resourceName))
+
+			Expect(recurringSilence.Status.NextWindow.StartsAt.Time).To(BeTemporally("==", nextWindow))
+			Expect(recurringSilence.Status.PreviousWindow.StartsAt.Time).To(BeTemporally("==", nextWindow.Add(-time.Hour)))
+			Expect(recurringSilence.Status.Silences).To(ContainElement(silence.Name))
+			Expect(meta.IsStatusConditionTrue(recurringSilence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionReady)).To(BeTrue())
+		})
+
+		It("should delete Silence objects of past windows", func() {
+			Expect(k8sClient.Create(ctx, recurringSilence)).To(Succeed())
+			pastWindow := time.Now().Truncate(time.Hour).Add(-24 * time.Hour)
+			silence := &alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      recurringSilenceChildName(resourceName, pastWindow),
+					Namespace: "default",
+					Labels:    map[string]string{recurringSilenceLabel: resourceName},
+				},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					MatchLabels: map[string]string{"alertname": "KubeNodeNotReady"},
+					StartsAt:    metav1.NewTime(pastWindow),
+					EndsAt:      metav1.NewTime(pastWindow.Add(30 * time.Minute)),
+				},
+			}
+			Expect(controllerutil.SetControllerReference(recurringSilence, silence, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+
+			reconcileRecurringSilence()
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(silence), silence))).To(BeTrue())
+			Expect(recurringSilence.Status.Silences).NotTo(ContainElement(silence.Name))
+		})
+
+		It("should not create silences while suspended", func() {
+			recurringSilence.Spec.Suspend = true
+			Expect(k8sClient.Create(ctx, recurringSilence)).To(Succeed())
+			reconcileRecurringSilence()
+
+			silences := &alertmanagerprometheusiov1alpha1.SilenceList{}
+			Expect(k8sClient.List(ctx, silences, client.InNamespace("default"), client.HasLabels{recurringSilenceLabel})).To(Succeed())
+			Expect(silences.Items).To(BeEmpty())
+			Expect(recurringSilence.Status.NextWindow).To(BeNil())
+			Expect(meta.FindStatusCondition(recurringSilence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionReady).Reason).
+				To(Equal(reasonSuspended))
+		})
+
+		It("should interpret the schedule in its time zone", func() {
+			recurringSilence.Spec.Schedule = "0 22 * * 6"
+			recurringSilence.Spec.TimeZone = "Europe/Zurich"
+			Expect(k8sClient.Create(ctx, recurringSilence)).To(Succeed())
+			reconcileRecurringSilence()
+
+			location, err := time.LoadLocation("Europe/Zurich")
+			Expect(err).NotTo(HaveOccurred())
+			next := recurringSilence.Status.NextWindow.StartsAt.In(location)
+			Expect(next.Weekday()).To(Equal(time.Saturday))
+			Expect(next.Hour()).To(Equal(22))
+			Expect(recurringSilence.Status.PreviousWindow.StartsAt.Time).To(BeTemporally("==", next.AddDate(0, 0, -7)))
+		})
+
+		It("should report invalid schedules", func() {
+			recurringSilence.Spec.Schedule = "every saturday"
+			Expect(k8sClient.Create(ctx, recurringSilence)).To(Succeed())
+			reconcileRecurringSilence()
+
+			condition := meta.FindStatusCondition(recurringSilence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionReady)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(reasonInvalidSchedule))
+		})
+
+		It("should report specs which the Silences cannot be created with", func() {
+			recurringSilence.Spec.Matchers = []alertmanagerprometheusiov1alpha1.Matcher{
+				{Name: "instance", Value: "node-(1", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp},
+			}
+			Expect(k8sClient.Create(ctx, recurringSilence)).To(Succeed())
+			reconcileRecurringSilence()
+
+			condition := meta.FindStatusCondition(recurringSilence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionReady)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(reasonInvalidSpec))
+
+			By("reporting Silences rejected by the webhook")
+			recurringSilence.Spec.Matchers = nil
+			Expect(k8sClient.Update(ctx, recurringSilence)).To(Succeed())
+			controllerReconciler.Client = &rejectingClient{Client: k8sClient, reject: func(obj client.Object) error {
+				return errors.NewInvalid(alertmanagerprometheusiov1alpha1.GroupVersion.WithKind("Silence").GroupKind(), obj.GetName(),
+					field.ErrorList{field.Invalid(field.NewPath("spec"), "720h", "the silence must not last longer than 168h")})
+			}}
+			reconcileRecurringSilence()
+
+			condition = meta.FindStatusCondition(recurringSilence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionReady)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(reasonInvalidSpec))
+			Expect(condition.Message).To(ContainSubstring("must not last longer than 168h"))
+		})
+	})
+
+	Context("When naming the Silence objects of a RecurringSilence", func() {
+		startsAt := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+		It("should suffix the name with the scheduled time", func() {
+			Expect(recurringSilenceChildName("weekly-patching", startsAt)).To(Equal("weekly-patching-28663920"))
+			Expect(recurringSilenceLabelValue("weekly-patching")).To(Equal("weekly-patching"))
+		})
+
+		It("should keep the names of long RecurringSilences valid and unique", func() {
+			longName := strings.Repeat("maintenance.", 20) + "cluster-a"
+			otherName := strings.Repeat("maintenance.", 20) + "cluster-b"
+			Expect(longName).To(HaveLen(249))
+
+			name := recurringSilenceChildName(longName, startsAt)
+			Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+			Expect(name).To(HaveSuffix("-28663920"))
+			Expect(name).NotTo(Equal(recurringSilenceChildName(otherName, startsAt)))
+
+			labelValue := recurringSilenceLabelValue(longName)
+			Expect(validation.IsValidLabelValue(labelValue)).To(BeEmpty())
+			Expect(labelValue).NotTo(Equal(recurringSilenceLabelValue(otherName)))
+		})
+	})
+
+	Context("When searching the previous time of a schedule", func() {
+		It("should find times far in the past", func() {
+			schedule, err := cron.ParseStandard("0 0 1 1 *")
+			Expect(err).NotTo(HaveOccurred())
+			now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+			Expect(previousScheduleTime(schedule, now)).To(Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("should include the given time", func() {
+			schedule, err := cron.ParseStandard("*/15 * * * *")
+			Expect(err).NotTo(HaveOccurred())
+			now := time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC)
+			Expect(previousScheduleTime(schedule, now)).To(Equal(now))
+		})
+	})
+})
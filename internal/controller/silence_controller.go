@@ -17,13 +17,27 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"k8s.io/apimachinery/pkg/api/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -36,6 +50,56 @@ import (
 	"github.com/jacksgt/alert-operator/internal/alertmanagerapi"
 )
 
+const (
+	// silenceFinalizer ensures the silence is removed from Alertmanager before the Silence object is deleted.
+	silenceFinalizer = "alert-operator"
+	// silenceIDLabel contains the ID Alertmanager assigned to the silence.
+	silenceIDLabel = "alertmanager.prometheus.io/silenceID"
+	// silenceOwnerLabel indicates whether the silence is authored in Kubernetes or in Alertmanager.
+	silenceOwnerLabel        = alertmanagerprometheusiov1alpha1.SilenceOwnerLabel
+	silenceOwnerKubernetes   = alertmanagerprometheusiov1alpha1.SilenceOwnerKubernetes
+	silenceOwnerAlertmanager = alertmanagerprometheusiov1alpha1.SilenceOwnerAlertmanager
+	// forceDeleteAnnotation allows removing the finalizer of a Silence object without deleting the silence from Alertmanager.
+	forceDeleteAnnotation = "alertmanager.prometheus.io/force-delete"
+	// deletionAttemptsAnnotation counts the failed attempts to delete the silence from Alertmanager.
+	deletionAttemptsAnnotation = "alertmanager.prometheus.io/deletion-attempts"
+
+	// defaultMaxDeletionAttempts is used when SilenceReconciler.MaxDeletionAttempts is not set.
+	defaultMaxDeletionAttempts = 10
+	// deletionBackoff is the delay after the first failed deletion attempt, it doubles with every attempt.
+	deletionBackoff = 5 * time.Second
+	// maxDeletionBackoff caps the delay between two deletion attempts.
+	maxDeletionBackoff = 5 * time.Minute
+	// maxSilencedAlertsListed caps the number of alerts listed in the status of a Silence object.
+	maxSilencedAlertsListed = 50
+
+	// SilenceImportPolicyReadOnly imports silences created in Alertmanager as read-only Silence objects.
+	SilenceImportPolicyReadOnly = "import-read-only"
+	// SilenceImportPolicyAdopt imports silences created in Alertmanager as Silence objects managed by Kubernetes.
+	SilenceImportPolicyAdopt = "adopt"
+	// SilenceImportPolicyIgnore leaves silences created in Alertmanager alone.
+	SilenceImportPolicyIgnore = "ignore"
+	// SilenceImportPolicyExpireUnmanaged expires all silences in Alertmanager which are not managed by a Silence object.
+	SilenceImportPolicyExpireUnmanaged = "expire-unmanaged"
+
+	// Reasons for the status conditions of Silence objects
+	reasonSynced            = "Synced"
+	reasonActive            = "Active"
+	reasonPending           = "Pending"
+	reasonExpired           = "Expired"
+	reasonInvalidSpec       = "InvalidSpec"
+	reasonEndsInPast        = "EndsInPast"
+	reasonAlertmanagerError = "AlertmanagerError"
+	reasonSilenceMissing    = "SilenceMissing"
+	reasonSilenceModified   = "SilenceModified"
+	reasonNoDrift           = "NoDrift"
+	reasonDeleted           = "Deleted"
+	reasonDeletionFailed    = "DeletionFailed"
+	reasonForceDeleted      = "ForceDeleted"
+	reasonAlertsSilenced    = "AlertsSilenced"
+	reasonNoAlertsSilenced  = "NoAlertsSilenced"
+)
+
 // SilenceReconciler reconciles a Silence object
 type SilenceReconciler struct {
 	client.Client
@@ -43,11 +107,28 @@ type SilenceReconciler struct {
 	Namespace          string
 	SyncChannel        chan event.GenericEvent
 	AlertmanagerClient *alertmanagerapi.APIClient
+	// Recorder emits Events for the Silence objects, e.g. when a silence had to be restored in Alertmanager (optional).
+	Recorder record.EventRecorder
+	// MaxDeletionAttempts limits how often deleting a silence from Alertmanager is retried before the finalizer is
+	// removed anyway. If zero, defaultMaxDeletionAttempts is used.
+	MaxDeletionAttempts int
+	// ImportPolicy decides what happens to silences which exist in Alertmanager, but not as a Silence object.
+	// One of SilenceImportPolicyReadOnly (default), SilenceImportPolicyAdopt, SilenceImportPolicyIgnore
+	// or SilenceImportPolicyExpireUnmanaged.
+	ImportPolicy string
+	// NamespaceLabel is the alert label which Silence objects outside of Namespace are restricted to, i.e. a silence in
+	// a tenant namespace only applies to alerts whose NamespaceLabel equals that namespace. If empty, silences are not restricted.
+	NamespaceLabel string
+	// UnusedThreshold is how long an active silence may mute no alerts before its Unused condition is set.
+	// If zero, the Unused condition is not reported.
+	UnusedThreshold time.Duration
 }
 
 // +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=silences,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=silences/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=silences/finalizers,verbs=update
+// +kubebuilder:rbac:groups=alertmanager.prometheus.io.alertmanager.prometheus.io,resources=alerts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -64,41 +145,7 @@ func (r *SilenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	// sync existing silences from alertmanager server
 	if req.NamespacedName.Name == "" && req.NamespacedName.Namespace == "" {
-		log.V(5).Info("Running reconciliation to update all Silences from Alertmanager")
-		silencesResp, httpResp, err := r.AlertmanagerClient.SilenceAPI.GetSilences(ctx).Execute()
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-		_ = httpResp
-		log.V(5).Info(fmt.Sprintf("Alertmanager returned %d silences", len(silencesResp)))
-		for _, s := range silencesResp {
-			silence := alertmanagerprometheusiov1alpha1.Silence{}
-			silence.Name = s.GetId() // TODO: better name for silences?
-			silence.Namespace = r.Namespace
-			silence.Spec.Comment = s.GetComment()
-			silence.Spec.CreatedBy = s.GetCreatedBy()
-			silence.Spec.StartsAt = metav1.NewTime(s.GetStartsAt())
-			silence.Spec.EndsAt = metav1.NewTime(s.GetEndsAt())
-			silence.Spec.MatchLabels = map[string]string{}
-			for _, matcher := range s.GetMatchers() {
-				silence.Spec.MatchLabels[matcher.Name] = matcher.Value
-				// TODO: handle regexes
-			}
-
-			fmt.Printf("FOO: %+v\n", silence)
-			err := r.Update(ctx, &silence)
-			if errors.IsNotFound(err) {
-				// need to create it first
-				err = r.Create(ctx, &silence)
-			}
-			if err != nil {
-				log.Error(err, "Failed to create or update silence", "name", silence.Name, "namespace", silence.Namespace)
-				return ctrl.Result{Requeue: true}, err
-			}
-			log.V(5).Info("Created Silence in Kubernetes after fetching it from Alertmanager", "name", silence.Name, "namespace", silence.Namespace)
-		}
-		// all good, exit reconciliation here
-		return ctrl.Result{}, nil
+		return r.syncAllSilences(ctx)
 	}
 
 	// Fetch the Silence
@@ -110,25 +157,25 @@ func (r *SilenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	owner := r.silenceOwner(silence)
+
 	// Deletions: On resource deletion, it deletes from Authzsvc API. If it doesn't exist there, it removes the finalizer
 	if silence.GetDeletionTimestamp() != nil {
 		// should only be deleted from API if there is a finalizer
-		if !controllerutil.ContainsFinalizer(&silence, "alert-operator") {
+		if !controllerutil.ContainsFinalizer(&silence, silenceFinalizer) {
 			// nothing to do for us
 			return ctrl.Result{}, nil
 		}
 
-		httpResp, err := r.AlertmanagerClient.SilenceAPI.DeleteSilence(ctx, silence.Status.SilenceId).Execute()
-		_ = httpResp
-		if err != nil {
-			log.Error(err, "Failed to delete silence")
-			// retry later
-			return ctrl.Result{Requeue: true}, err
+		// read-only silences are left alone in Alertmanager
+		if owner == silenceOwnerKubernetes {
+			if result, done := r.deleteAlertmanagerSilence(ctx, &silence); !done {
+				return result, nil
+			}
 		}
 
-		controllerutil.RemoveFinalizer(&silence, "alert-operator")
-		err = r.Update(ctx, &silence)
-		if err != nil {
+		controllerutil.RemoveFinalizer(&silence, silenceFinalizer)
+		if err := r.Update(ctx, &silence); err != nil {
 			log.Error(err, "Failed to remove finalizer")
 			return ctrl.Result{Requeue: true}, err
 		}
@@ -137,43 +184,837 @@ func (r *SilenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
-	return ctrl.Result{}, nil
+	if owner == silenceOwnerAlertmanager {
+		return r.reconcileReadOnlySilence(ctx, &silence)
+	}
 
-	s := generateAlertmanagerSilence(silence)
-	var silenceId string
+	// make sure we get a chance to delete the silence from Alertmanager before the object is gone
+	if !controllerutil.ContainsFinalizer(&silence, silenceFinalizer) || silence.Labels[silenceOwnerLabel] != owner {
+		controllerutil.AddFinalizer(&silence, silenceFinalizer)
+		setLabel(&silence, silenceOwnerLabel, owner)
+		if err := r.Update(ctx, &silence); err != nil {
+			log.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
 
-	// check if silence already exists in Alertmanager
-	if silence.Status.SilenceId != "" {
-		silenceResp, httpResp, err := r.AlertmanagerClient.SilenceAPI.GetSilence(ctx, silence.Status.SilenceId).Execute()
-		if err != nil {
-			return ctrl.Result{Requeue: true}, err
+	if err := validateSilenceSpec(silence.Spec); err != nil {
+		// the spec needs to be fixed by the user, retrying won't help
+		log.Error(err, "Invalid spec in Silence", "name", silence.Name, "namespace", silence.Namespace)
+		return ctrl.Result{}, r.updateSilenceStatus(ctx, &silence, nil, reasonInvalidSpec, err)
+	}
+
+	s := r.alertmanagerSilence(silence)
+	silenceId := r.silenceID(silence)
+
+	// check if silence already exists in Alertmanager and is up-to-date
+	var current *alertmanagerapi.GettableSilence
+	needsUpdate := true
+	drift := ""
+	if silenceId != "" {
+		silenceResp, httpResp, err := r.AlertmanagerClient.SilenceAPI.GetSilence(ctx, silenceId).Execute()
+		switch {
+		case httpResp != nil && httpResp.StatusCode == http.StatusNotFound:
+			// Alertmanager lost the silence (e.g. it was restarted without persistent storage), so it needs to be recreated
+			log.Info("Silence no longer exists in Alertmanager", "name", silence.Name, "namespace", silence.Namespace, "silenceID", silenceId)
+			drift = reasonSilenceMissing
+		case err != nil:
+			return ctrl.Result{Requeue: true}, r.updateSilenceStatus(ctx, &silence, nil, reasonAlertmanagerError, err)
+		default:
+			current = silenceResp
+			needsUpdate = silenceNeedsUpdate(s, *current)
+			// if the spec has not changed since the last successful sync, the silence must have been modified in Alertmanager
+			if needsUpdate && silence.Status.ObservedGeneration == silence.Generation &&
+				meta.IsStatusConditionTrue(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionSynced) {
+				drift = reasonSilenceModified
+			}
+		}
+	}
+
+	if needsUpdate && s.EndsAt.Before(time.Now()) {
+		// Alertmanager refuses to create silences that end in the past, there is nothing left to do for us
+		log.V(5).Info("Not creating or updating silence that has already ended", "name", silence.Name, "namespace", silence.Namespace)
+		return ctrl.Result{}, r.updateSilenceStatus(ctx, &silence, current, reasonEndsInPast,
+			fmt.Errorf("The silence ends in the past (%s), it cannot be created or updated in Alertmanager", s.EndsAt.Format(time.RFC3339)))
+	}
+
+	if needsUpdate {
+		// create silence in Alertmanager, or update the existing one (by specifying its ID)
+		postableSilence := convertSilenceToPost(s)
+		if silenceId != "" && drift != reasonSilenceMissing {
+			postableSilence.SetId(silenceId)
 		}
-		_ = httpResp
-		// TODO: silence already exists, but let's make sure it's up-to-date
-		silenceId = silenceResp.GetId()
-	} else {
-		// create silence in Alertmanager
 		silenceResp, httpResp, err := r.AlertmanagerClient.SilenceAPI.
 			PostSilences(ctx).
-			Silence(convertSilenceToPost(s)).
+			Silence(postableSilence).
 			Execute()
 		if err != nil {
- 			log.Error(err, "Failed to create silence")
-			return ctrl.Result{Requeue: true}, err
+			log.Error(err, "Failed to create or update silence")
+			return ctrl.Result{Requeue: true}, r.updateSilenceStatus(ctx, &silence, current, reasonAlertmanagerError, err)
 		}
 		_ = httpResp
+		previousId := silenceId
+		// Alertmanager might assign a new ID when updating a silence (e.g. when the matchers have changed)
 		silenceId = silenceResp.GetSilenceID()
+		log.V(5).Info("Posted silence to Alertmanager", "name", silence.Name, "namespace", silence.Namespace, "silenceID", silenceId)
+
+		switch drift {
+		case reasonSilenceMissing:
+			r.recordEventf(&silence, corev1.EventTypeWarning, drift,
+				"Silence %s no longer existed in Alertmanager, recreated it as %s", previousId, silenceId)
+		case reasonSilenceModified:
+			r.recordEventf(&silence, corev1.EventTypeWarning, drift,
+				"Silence %s was modified in Alertmanager, restored it from the spec", previousId)
+		}
+
+		if silenceId != silence.Status.SilenceId {
+			if err := r.saveSilenceID(ctx, &silence, silenceId); err != nil {
+				log.Error(err, "Failed to record silenceID in Silence status", "silenceID", silenceId)
+				return ctrl.Result{}, err
+			}
+		}
 	}
 
 	// populate the object
+	if silence.Labels[silenceIDLabel] != silenceId {
+		setLabel(&silence, silenceIDLabel, silenceId)
+		if err := r.Update(ctx, &silence); err != nil {
+			log.Error(err, "Failed to set silenceID label")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if needsUpdate {
+		// fetch the silence again to learn about its current state
+		silenceResp, _, err := r.AlertmanagerClient.SilenceAPI.GetSilence(ctx, silenceId).Execute()
+		if err != nil {
+			silence.Status.SilenceId = silenceId
+			return ctrl.Result{Requeue: true}, r.updateSilenceStatus(ctx, &silence, nil, reasonAlertmanagerError, err)
+		}
+		current = silenceResp
+	}
+
+	driftCondition := metav1.Condition{
+		Type:               alertmanagerprometheusiov1alpha1.SilenceConditionDrifted,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: silence.Generation,
+		Reason:             reasonNoDrift,
+		Message:            "The silence in Alertmanager matches the spec",
+	}
+	switch drift {
+	case reasonSilenceMissing:
+		driftCondition.Status = metav1.ConditionTrue
+		driftCondition.Reason = drift
+		driftCondition.Message = fmt.Sprintf("The silence no longer existed in Alertmanager and was recreated at %s", time.Now().Format(time.RFC3339))
+	case reasonSilenceModified:
+		driftCondition.Status = metav1.ConditionTrue
+		driftCondition.Reason = drift
+		driftCondition.Message = fmt.Sprintf("The silence was modified in Alertmanager and restored from the spec at %s", time.Now().Format(time.RFC3339))
+	}
+	meta.SetStatusCondition(&silence.Status.Conditions, driftCondition)
+
+	if err := r.updateSilenceStatus(ctx, &silence, current, "", nil); err != nil {
+		log.Error(err, "Failed to update Silence status")
+		return ctrl.Result{}, err
+	}
+
+	return requeueForStateChange(silence.Status.State, current), nil
+}
+
+// deleteAlertmanagerSilence expires the silence of a deleted Silence object in Alertmanager. It returns true when
+// the finalizer can be removed: the silence is gone, has already expired, or we gave up on it.
+// Failed attempts are retried with exponential backoff, up to MaxDeletionAttempts times.
+func (r *SilenceReconciler) deleteAlertmanagerSilence(ctx context.Context, silence *alertmanagerprometheusiov1alpha1.Silence) (ctrl.Result, bool) {
+	log := log.FromContext(ctx)
+
+	if silence.Annotations[forceDeleteAnnotation] == "true" {
+		r.recordEventf(silence, corev1.EventTypeWarning, reasonForceDeleted,
+			"Removing finalizer without deleting silence %s from Alertmanager", silence.Status.SilenceId)
+		return ctrl.Result{}, true
+	}
+
+	silenceId := r.silenceID(*silence)
+	if silenceId == "" {
+		r.recordEventf(silence, corev1.EventTypeNormal, reasonDeleted, "The silence was never created in Alertmanager")
+		return ctrl.Result{}, true
+	}
+
+	// the retry schedule is derived from the deletion timestamp, since updating the attempts triggers a reconcile
+	attempts, _ := strconv.Atoi(silence.Annotations[deletionAttemptsAnnotation])
+	if retryAt := deletionRetryTime(silence.GetDeletionTimestamp().Time, attempts); time.Now().Before(retryAt) {
+		return ctrl.Result{RequeueAfter: time.Until(retryAt)}, false
+	}
+
+	httpResp, err := r.AlertmanagerClient.SilenceAPI.DeleteSilence(ctx, silenceId).Execute()
+	if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+		r.recordEventf(silence, corev1.EventTypeNormal, reasonDeleted, "Silence %s no longer exists in Alertmanager", silenceId)
+		return ctrl.Result{}, true
+	}
+	if err == nil {
+		r.recordEventf(silence, corev1.EventTypeNormal, reasonDeleted, "Expired silence %s in Alertmanager", silenceId)
+		return ctrl.Result{}, true
+	}
+
+	// Alertmanager refuses to expire silences which have already expired
+	if current, _, getErr := r.AlertmanagerClient.SilenceAPI.GetSilence(ctx, silenceId).Execute(); getErr == nil &&
+		current.Status.GetState() == alertmanagerprometheusiov1alpha1.SilenceStateExpired {
+		r.recordEventf(silence, corev1.EventTypeNormal, reasonDeleted, "Silence %s has already expired in Alertmanager", silenceId)
+		return ctrl.Result{}, true
+	}
+
+	attempts++
+	maxAttempts := r.MaxDeletionAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxDeletionAttempts
+	}
+	if attempts >= maxAttempts {
+		log.Error(err, "Giving up deleting silence", "name", silence.Name, "namespace", silence.Namespace, "silenceID", silenceId)
+		r.recordEventf(silence, corev1.EventTypeWarning, reasonDeletionFailed,
+			"Giving up deleting silence %s from Alertmanager after %d attempts: %s", silenceId, attempts, alertmanagerErrorMessage(err))
+		return ctrl.Result{}, true
+	}
+
+	log.Error(err, "Failed to delete silence", "name", silence.Name, "namespace", silence.Namespace, "silenceID", silenceId, "attempts", attempts)
+	r.recordEventf(silence, corev1.EventTypeWarning, reasonDeletionFailed,
+		"Failed to delete silence %s from Alertmanager (attempt %d of %d): %s", silenceId, attempts, maxAttempts, alertmanagerErrorMessage(err))
+	if silence.Annotations == nil {
+		silence.Annotations = map[string]string{}
+	}
+	silence.Annotations[deletionAttemptsAnnotation] = strconv.Itoa(attempts)
+	if err := r.Update(ctx, silence); err != nil {
+		log.Error(err, "Failed to record deletion attempt")
+		return ctrl.Result{Requeue: true}, false
+	}
+	return ctrl.Result{RequeueAfter: time.Until(deletionRetryTime(silence.GetDeletionTimestamp().Time, attempts))}, false
+}
+
+// deletionRetryTime returns when the next attempt to delete a silence should be made after the given number of
+// failed attempts, with exponential backoff starting from the deletion time.
+func deletionRetryTime(deletedAt time.Time, attempts int) time.Time {
+	retryAt := deletedAt
+	backoff := deletionBackoff
+	for i := 0; i < attempts; i++ {
+		retryAt = retryAt.Add(backoff)
+		backoff = min(2*backoff, maxDeletionBackoff)
+	}
+	return retryAt
+}
+
+// reconcileReadOnlySilence refreshes the status of a Silence object which mirrors a silence created in Alertmanager.
+// Its spec is kept up-to-date by the full sync, it is never written to Alertmanager.
+func (r *SilenceReconciler) reconcileReadOnlySilence(ctx context.Context, silence *alertmanagerprometheusiov1alpha1.Silence) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	// the object does not need to be cleaned up in Alertmanager
+	if controllerutil.ContainsFinalizer(silence, silenceFinalizer) || silence.Labels[silenceOwnerLabel] != silenceOwnerAlertmanager {
+		controllerutil.RemoveFinalizer(silence, silenceFinalizer)
+		setLabel(silence, silenceOwnerLabel, silenceOwnerAlertmanager)
+		if err := r.Update(ctx, silence); err != nil {
+			log.Error(err, "Failed to update read-only Silence")
+			return ctrl.Result{}, err
+		}
+	}
+
+	silenceId := r.silenceID(*silence)
+
+	current, _, err := r.AlertmanagerClient.SilenceAPI.GetSilence(ctx, silenceId).Execute()
+	if err != nil {
+		return ctrl.Result{Requeue: true}, r.updateSilenceStatus(ctx, silence, nil, reasonAlertmanagerError, err)
+	}
+	if err := r.updateSilenceStatus(ctx, silence, current, "", nil); err != nil {
+		log.Error(err, "Failed to update Silence status")
+		return ctrl.Result{}, err
+	}
+
+	return requeueForStateChange(silence.Status.State, current), nil
+}
+
+// requeueForStateChange returns a result which brings us back when the silence becomes active or expires
+// to refresh its status.
+func requeueForStateChange(state string, current *alertmanagerapi.GettableSilence) ctrl.Result {
+	switch state {
+	case alertmanagerprometheusiov1alpha1.SilenceStatePending:
+		return ctrl.Result{RequeueAfter: max(time.Until(current.GetStartsAt()), 0) + time.Second}
+	case alertmanagerprometheusiov1alpha1.SilenceStateActive:
+		return ctrl.Result{RequeueAfter: max(time.Until(current.GetEndsAt()), 0) + time.Second}
+	}
+	return ctrl.Result{}
+}
+
+// saveSilenceID records the ID of the silence which was just posted to Alertmanager in the status of the Silence
+// object, retrying on conflicts. Otherwise, the next reconcile would not know about the silence and post another one.
+func (r *SilenceReconciler) saveSilenceID(ctx context.Context, silence *alertmanagerprometheusiov1alpha1.Silence, silenceId string) error {
+	latest := &alertmanagerprometheusiov1alpha1.Silence{}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(silence), latest); err != nil {
+			return err
+		}
+		latest.Status.SilenceId = silenceId
+		return r.Status().Update(ctx, latest)
+	})
+	if err != nil {
+		return err
+	}
+	if latest.Generation != silence.Generation {
+		return fmt.Errorf("The spec of the Silence was changed while posting it to Alertmanager")
+	}
+
+	silence.ObjectMeta = latest.ObjectMeta
 	silence.Status.SilenceId = silenceId
-	setLabel(&silence, "alertmanager.prometheus.io/silenceID", silenceId)
-	controllerutil.AddFinalizer(&silence, "alert-operator")
-	// TODO: set status conditions
+	return nil
+}
+
+// recordEventf emits an Event for the Silence object, if a Recorder is configured.
+func (r *SilenceReconciler) recordEventf(silence runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(silence, eventType, reason, messageFmt, args...)
+}
+
+// silenceOwner returns whether the Silence object is authored in Kubernetes or mirrors a silence from Alertmanager.
+// Silences imported by previous versions of the operator have no owner label, but are named after their ID.
+func (r *SilenceReconciler) silenceOwner(silence alertmanagerprometheusiov1alpha1.Silence) string {
+	// silences are only imported into the namespace of the operator
+	if r.isTenantNamespace(silence.Namespace) {
+		return silenceOwnerKubernetes
+	}
+	if owner, ok := silence.Labels[silenceOwnerLabel]; ok {
+		return owner
+	}
+	if id := silence.Labels[silenceIDLabel]; id != "" && silence.Name == id && silence.Namespace == r.Namespace {
+		return silenceOwnerAlertmanager
+	}
+	return silenceOwnerKubernetes
+}
+
+// silenceID returns the ID of the silence in Alertmanager which belongs to the Silence object.
+// The label is set when a silence is imported from Alertmanager, before the status could be written. It is not trusted
+// in tenant namespaces, since tenants could point it at silences which do not belong to them.
+func (r *SilenceReconciler) silenceID(silence alertmanagerprometheusiov1alpha1.Silence) string {
+	if silence.Status.SilenceId != "" || r.isTenantNamespace(silence.Namespace) {
+		return silence.Status.SilenceId
+	}
+	return silence.Labels[silenceIDLabel]
+}
+
+// isTenantNamespace returns whether Silence objects in the namespace are created by tenants rather than by the operator
+// or the platform administrators.
+func (r *SilenceReconciler) isTenantNamespace(namespace string) bool {
+	return r.Namespace != "" && namespace != r.Namespace
+}
+
+// alertmanagerSilence returns the silence which should exist in Alertmanager for the Silence object.
+// Silences in tenant namespaces are restricted to alerts from that namespace.
+func (r *SilenceReconciler) alertmanagerSilence(silence alertmanagerprometheusiov1alpha1.Silence) alertmanagerapi.Silence {
+	s := generateAlertmanagerSilence(silence)
+	if r.NamespaceLabel == "" || !r.isTenantNamespace(silence.Namespace) {
+		return s
+	}
+
+	namespaceMatcher := alertmanagerapi.NewMatcher(r.NamespaceLabel, silence.Namespace, false)
+	for _, m := range s.Matchers {
+		if equalMatchers([]alertmanagerapi.Matcher{m}, []alertmanagerapi.Matcher{*namespaceMatcher}) {
+			return s
+		}
+	}
+	s.Matchers = append(s.Matchers, *namespaceMatcher)
+	return s
+}
+
+// updateSilenceStatus writes the status of the Silence object based on the silence in Alertmanager (if known)
+// and the error that occurred while synchronizing it (if any).
+func (r *SilenceReconciler) updateSilenceStatus(ctx context.Context, silence *alertmanagerprometheusiov1alpha1.Silence,
+	current *alertmanagerapi.GettableSilence, reason string, syncErr error) error {
+	status := &silence.Status
+	status.ObservedGeneration = silence.Generation
+	// report the absolute time window, since it may be derived from the creation time and duration
+	status.StartsAt, status.EndsAt = nil, nil
+	if validateSilenceWindow(silence.Spec) == nil {
+		startsAt, endsAt := silenceWindow(*silence)
+		status.StartsAt = &metav1.Time{Time: startsAt}
+		status.EndsAt = &metav1.Time{Time: endsAt}
+	}
+	if current != nil {
+		status.SilenceId = current.GetId()
+		status.State = current.Status.GetState()
+		status.UpdatedAt = &metav1.Time{Time: current.GetUpdatedAt()}
+	}
+
+	setCondition := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			ObservedGeneration: silence.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	if syncErr != nil {
+		message := alertmanagerErrorMessage(syncErr)
+		setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionSynced, metav1.ConditionFalse, reason, message)
+		setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionReady, metav1.ConditionFalse, reason, message)
+	} else {
+		setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionSynced, metav1.ConditionTrue, reasonSynced,
+			"The silence is in sync with Alertmanager")
+	}
+
+	if current == nil {
+		// nothing new to report about the state of the silence
+		if meta.FindStatusCondition(status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionExpired) == nil {
+			setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionExpired, metav1.ConditionUnknown, reason,
+				"The state of the silence in Alertmanager is unknown")
+		}
+		return r.Status().Update(ctx, silence)
+	}
+
+	switch status.State {
+	case alertmanagerprometheusiov1alpha1.SilenceStateActive:
+		if syncErr == nil {
+			setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionReady, metav1.ConditionTrue, reasonActive,
+				"The silence is active")
+		}
+		setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionExpired, metav1.ConditionFalse, reasonActive,
+			fmt.Sprintf("The silence ends at %s", current.GetEndsAt().Format(time.RFC3339)))
+	case alertmanagerprometheusiov1alpha1.SilenceStatePending:
+		if syncErr == nil {
+			setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionReady, metav1.ConditionFalse, reasonPending,
+				fmt.Sprintf("The silence starts at %s", current.GetStartsAt().Format(time.RFC3339)))
+		}
+		setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionExpired, metav1.ConditionFalse, reasonPending,
+			fmt.Sprintf("The silence ends at %s", current.GetEndsAt().Format(time.RFC3339)))
+	case alertmanagerprometheusiov1alpha1.SilenceStateExpired:
+		if syncErr == nil {
+			setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionReady, metav1.ConditionFalse, reasonExpired,
+				"The silence has expired")
+		}
+		setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionExpired, metav1.ConditionTrue, reasonExpired,
+			fmt.Sprintf("The silence ended at %s", current.GetEndsAt().Format(time.RFC3339)))
+	default:
+		setCondition(alertmanagerprometheusiov1alpha1.SilenceConditionExpired, metav1.ConditionUnknown, reason,
+			"The state of the silence in Alertmanager is unknown")
+	}
+
+	return r.Status().Update(ctx, silence)
+}
+
+// alertmanagerErrorMessage returns a human-readable message for errors returned by the Alertmanager API,
+// including the response body which usually contains the actual reason.
+func alertmanagerErrorMessage(err error) string {
+	var apiErr *alertmanagerapi.GenericOpenAPIError
+	if errors.As(err, &apiErr) && len(apiErr.Body()) > 0 {
+		body := strings.TrimSpace(string(apiErr.Body()))
+		if len(body) > 1024 {
+			body = body[:1024] + "..."
+		}
+		return fmt.Sprintf("Alertmanager returned %s: %s", apiErr.Error(), body)
+	}
+	return err.Error()
+}
+
+// syncAllSilences handles all silences from Alertmanager which are not managed by a Silence object according to
+// the import policy and removes read-only Silence objects whose silence no longer exists in Alertmanager.
+func (r *SilenceReconciler) syncAllSilences(ctx context.Context) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.V(5).Info("Running reconciliation to update all Silences from Alertmanager")
+
+	silencesResp, httpResp, err := r.AlertmanagerClient.SilenceAPI.GetSilences(ctx).Execute()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	_ = httpResp
+	log.V(5).Info(fmt.Sprintf("Alertmanager returned %d silences", len(silencesResp)))
 
+	// silences created from Silence objects must not be imported again
+	silenceList := alertmanagerprometheusiov1alpha1.SilenceList{}
+	if err := r.List(ctx, &silenceList, client.HasLabels{silenceIDLabel}); err != nil {
+		return ctrl.Result{}, err
+	}
+	knownSilences := map[string]alertmanagerprometheusiov1alpha1.Silence{}
+	for _, silence := range silenceList.Items {
+		if id := r.silenceID(silence); id != "" {
+			knownSilences[id] = silence
+		}
+	}
+
+	// a single silence which cannot be synced must not hold up all the others
+	var errs []error
+	existingSilences := map[string]bool{}
+	for _, s := range silencesResp {
+		existingSilences[s.GetId()] = true
+
+		known, ok := knownSilences[s.GetId()]
+		if ok && r.silenceOwner(known) == silenceOwnerKubernetes {
+			// silence is managed by a Silence object authored in Kubernetes, which must never be overwritten.
+			// Instead, changes made in Alertmanager are reverted.
+			if silenceNeedsUpdate(r.alertmanagerSilence(known), s) {
+				r.restoreSilence(ctx, known)
+			}
+			continue
+		}
+
+		switch r.ImportPolicy {
+		case SilenceImportPolicyIgnore:
+			continue
+		case SilenceImportPolicyExpireUnmanaged:
+			if s.Status.GetState() == alertmanagerprometheusiov1alpha1.SilenceStateExpired {
+				continue
+			}
+			if _, err := r.AlertmanagerClient.SilenceAPI.DeleteSilence(ctx, s.GetId()).Execute(); err != nil {
+				log.Error(err, "Failed to expire unmanaged silence", "silenceID", s.GetId())
+				errs = append(errs, err)
+				continue
+			}
+			log.Info("Expired silence which is not managed by a Silence object", "silenceID", s.GetId(), "createdBy", s.GetCreatedBy())
+			continue
+		}
+
+		// expired silences are only followed by the objects which mirror them already: Alertmanager keeps them until
+		// their retention ends, including the silences of deleted, updated or past recurring Silence objects
+		expired := s.Status.GetState() == alertmanagerprometheusiov1alpha1.SilenceStateExpired
+		if expired && !ok {
+			continue
+		}
+
+		// expired silences cannot be changed anymore, so they are only ever mirrored
+		owner := silenceOwnerAlertmanager
+		if r.ImportPolicy == SilenceImportPolicyAdopt && !expired {
+			owner = silenceOwnerKubernetes
+		}
+
+		err := r.importSilence(ctx, s, known, ok, owner)
+		if apierrors.IsInvalid(err) && owner == silenceOwnerKubernetes {
+			// e.g. silences which last longer than the webhook allows, they can still be mirrored
+			log.Info("Silence cannot be adopted, importing it read-only instead", "silenceID", s.GetId(), "error", err.Error())
+			err = r.importSilence(ctx, s, known, ok, silenceOwnerAlertmanager)
+		}
+		if err != nil {
+			log.Error(err, "Failed to import silence from Alertmanager", "silenceID", s.GetId())
+			errs = append(errs, err)
+		}
+	}
+
+	for _, silence := range silenceList.Items {
+		if existingSilences[r.silenceID(silence)] {
+			continue
+		}
+		// silences managed by Kubernetes are recreated when Alertmanager has lost them
+		if r.silenceOwner(silence) == silenceOwnerKubernetes {
+			r.restoreSilence(ctx, silence)
+			continue
+		}
+		// read-only objects are removed once Alertmanager has forgotten about their silence
+		if err := r.Delete(ctx, &silence); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to delete read-only Silence", "name", silence.Name, "namespace", silence.Namespace)
+			errs = append(errs, err)
+			continue
+		}
+		log.V(5).Info("Deleted read-only Silence which no longer exists in Alertmanager", "name", silence.Name, "namespace", silence.Namespace)
+	}
+
+	if err := r.syncSilencedAlerts(ctx, silencesResp); err != nil {
+		// the silenced alerts are purely informational, they must not hold up the sync of the silences themselves
+		log.Error(err, "Failed to update the silenced alerts of Silences")
+	}
+
+	if len(errs) > 0 {
+		return ctrl.Result{Requeue: true}, errors.Join(errs...)
+	}
+
+	// all good, exit reconciliation here
 	return ctrl.Result{}, nil
 }
 
+// importSilence creates or updates the Silence object for a silence from Alertmanager with the given owner.
+// known is the object which already belongs to the silence, if ok is true.
+func (r *SilenceReconciler) importSilence(ctx context.Context, s alertmanagerapi.GettableSilence,
+	known alertmanagerprometheusiov1alpha1.Silence, ok bool, owner string) error {
+	log := log.FromContext(ctx)
+
+	silence := alertmanagerprometheusiov1alpha1.Silence{}
+	silence.Namespace = r.Namespace
+	if ok {
+		silence.Name = known.Name
+		silence.Namespace = known.Namespace
+	} else {
+		name, err := r.importedSilenceName(ctx, s)
+		if err != nil {
+			return fmt.Errorf("Failed to find a name for imported silence: %w", err)
+		}
+		silence.Name = name
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, &silence, func() error {
+		silence.Spec.Comment = s.GetComment()
+		silence.Spec.CreatedBy = s.GetCreatedBy()
+		silence.Spec.StartsAt = metav1.NewTime(s.GetStartsAt())
+		silence.Spec.EndsAt = metav1.NewTime(s.GetEndsAt())
+		silence.Spec.MatchLabels = nil
+		silence.Spec.Matchers = nil
+		for _, matcher := range s.GetMatchers() {
+			silence.Spec.Matchers = append(silence.Spec.Matchers, convertAlertmanagerMatcher(matcher))
+		}
+		setLabel(&silence, silenceIDLabel, s.GetId())
+		setLabel(&silence, silenceOwnerLabel, owner)
+		return nil
+	})
+	if err != nil {
+		// keep the error as it is, so callers can tell invalid objects apart
+		return err
+	}
+
+	if silence.Status.SilenceId != s.GetId() {
+		silence.Status.SilenceId = s.GetId()
+		if err := r.Status().Update(ctx, &silence); err != nil {
+			return fmt.Errorf("Failed to update status of Silence %s/%s: %w", silence.Namespace, silence.Name, err)
+		}
+	}
+	log.V(5).Info("Created Silence in Kubernetes after fetching it from Alertmanager", "name", silence.Name, "namespace", silence.Namespace, "owner", owner)
+	return nil
+}
+
+// syncSilencedAlerts reports the alerts which are currently muted by each silence in the status of its Silence object,
+// based on the silencedBy field of the alerts in Alertmanager.
+func (r *SilenceReconciler) syncSilencedAlerts(ctx context.Context, silences []alertmanagerapi.GettableSilence) error {
+	log := log.FromContext(ctx)
+
+	alertsResp, _, err := r.AlertmanagerClient.AlertAPI.GetAlerts(ctx).Execute()
+	if err != nil {
+		return fmt.Errorf("Error fetching alerts from Alertmanager: %w", err)
+	}
+
+	// Alert objects are looked up by fingerprint, they may live in any namespace
+	alertList := alertmanagerprometheusiov1alpha1.AlertList{}
+	if err := r.List(ctx, &alertList, client.HasLabels{fingerprintLabel}); err != nil {
+		return fmt.Errorf("Failed to list Alerts: %w", err)
+	}
+	alertObjects := map[string]*alertmanagerprometheusiov1alpha1.Alert{}
+	for i := range alertList.Items {
+		alertObjects[alertList.Items[i].Labels[fingerprintLabel]] = &alertList.Items[i]
+	}
+
+	silencedAlerts := map[string][]alertmanagerprometheusiov1alpha1.SilencedAlert{}
+	for _, a := range alertsResp {
+		silenced := alertmanagerprometheusiov1alpha1.SilencedAlert{
+			Fingerprint: a.GetFingerprint(),
+			AlertName:   a.GetLabels()["alertname"],
+		}
+		if alertObj, ok := alertObjects[a.GetFingerprint()]; ok {
+			silenced.Name = alertObj.Name
+			silenced.Namespace = alertObj.Namespace
+		}
+		for _, id := range a.Status.GetSilencedBy() {
+			silencedAlerts[id] = append(silencedAlerts[id], silenced)
+		}
+	}
+
+	silenceStates := map[string]string{}
+	for _, s := range silences {
+		silenceStates[s.GetId()] = s.Status.GetState()
+	}
+
+	// the objects may have been changed by the sync, so they are listed again
+	silenceList := alertmanagerprometheusiov1alpha1.SilenceList{}
+	if err := r.List(ctx, &silenceList, client.HasLabels{silenceIDLabel}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range silenceList.Items {
+		silence := &silenceList.Items[i]
+		id := r.silenceID(*silence)
+		state, ok := silenceStates[id]
+		if !ok || silence.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		previous := silence.Status.DeepCopy()
+		r.setSilencedAlerts(silence, state, silencedAlerts[id], now)
+		if apiequality.Semantic.DeepEqual(previous, &silence.Status) {
+			continue
+		}
+		if err := r.Status().Update(ctx, silence); err != nil {
+			// conflicts are resolved by the next sync
+			if !apierrors.IsConflict(err) {
+				log.Error(err, "Failed to update silenced alerts", "name", silence.Name, "namespace", silence.Namespace)
+			}
+			continue
+		}
+
+		if meta.IsStatusConditionTrue(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionUnused) &&
+			!meta.IsStatusConditionTrue(previous.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionUnused) {
+			r.recordEventf(silence, corev1.EventTypeWarning, reasonNoAlertsSilenced,
+				"The silence has not muted any alerts since %s", silence.Status.UnusedSince.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// setSilencedAlerts updates the silenced alerts and the Unused condition in the status of the Silence object.
+// Only active silences can mute alerts, for all other silences these fields are cleared.
+func (r *SilenceReconciler) setSilencedAlerts(silence *alertmanagerprometheusiov1alpha1.Silence, state string,
+	alerts []alertmanagerprometheusiov1alpha1.SilencedAlert, now time.Time) {
+	status := &silence.Status
+	if state != alertmanagerprometheusiov1alpha1.SilenceStateActive {
+		status.SilencedAlerts = 0
+		status.Alerts = nil
+		status.UnusedSince = nil
+		meta.RemoveStatusCondition(&status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionUnused)
+		return
+	}
+
+	// Alertmanager does not return the alerts in a stable order
+	slices.SortFunc(alerts, func(a, b alertmanagerprometheusiov1alpha1.SilencedAlert) int {
+		return cmp.Or(cmp.Compare(a.AlertName, b.AlertName), cmp.Compare(a.Fingerprint, b.Fingerprint))
+	})
+	status.SilencedAlerts = int32(len(alerts))
+	status.Alerts = alerts[:min(len(alerts), maxSilencedAlertsListed)]
+	if len(alerts) > 0 {
+		status.UnusedSince = nil
+	} else if status.UnusedSince == nil {
+		status.UnusedSince = &metav1.Time{Time: now}
+	}
+
+	if r.UnusedThreshold <= 0 {
+		meta.RemoveStatusCondition(&status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionUnused)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               alertmanagerprometheusiov1alpha1.SilenceConditionUnused,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: silence.Generation,
+		Reason:             reasonAlertsSilenced,
+		Message:            fmt.Sprintf("The silence mutes %d alert(s)", len(alerts)),
+	}
+	if status.UnusedSince != nil {
+		condition.Reason = reasonNoAlertsSilenced
+		condition.Message = fmt.Sprintf("The silence has not muted any alerts since %s", status.UnusedSince.Format(time.RFC3339))
+		if now.Sub(status.UnusedSince.Time) >= r.UnusedThreshold {
+			condition.Status = metav1.ConditionTrue
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// restoreSilence reconciles a Silence object managed by Kubernetes whose silence in Alertmanager has drifted from the spec.
+// Silences which are being deleted, have an invalid spec or have already ended are left alone.
+func (r *SilenceReconciler) restoreSilence(ctx context.Context, silence alertmanagerprometheusiov1alpha1.Silence) {
+	log := log.FromContext(ctx)
+
+	if silence.GetDeletionTimestamp() != nil || validateSilenceSpec(silence.Spec) != nil {
+		return
+	}
+	if _, endsAt := silenceWindow(silence); endsAt.Before(time.Now()) {
+		return
+	}
+
+	log.V(5).Info("Restoring silence in Alertmanager", "name", silence.Name, "namespace", silence.Namespace)
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&silence)}); err != nil {
+		log.Error(err, "Failed to restore silence in Alertmanager", "name", silence.Name, "namespace", silence.Namespace)
+	}
+}
+
+// importedSilenceName returns the name of the Silence object for a silence imported from Alertmanager,
+// i.e. the first candidate name which is not yet taken by another silence.
+func (r *SilenceReconciler) importedSilenceName(ctx context.Context, s alertmanagerapi.GettableSilence) (string, error) {
+	for _, name := range silenceNameCandidates(s) {
+		existing := alertmanagerprometheusiov1alpha1.Silence{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: r.Namespace}, &existing)
+		if apierrors.IsNotFound(err) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if existing.Labels[silenceIDLabel] == s.GetId() {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("All names for silence %s are already taken", s.GetId())
+}
+
+// silenceNameCandidates returns human-readable names for a silence imported from Alertmanager, e.g. "alertname-kubejobfailed-3f2a".
+// The names are derived from the matchers and comment of the silence, so they are stable across syncs.
+// Longer hashes are used in case of collisions, the last resort is the (unique) ID of the silence.
+func silenceNameCandidates(s alertmanagerapi.GettableSilence) []string {
+	matchers := slices.Clone(s.GetMatchers())
+	slices.SortFunc(matchers, func(a, b alertmanagerapi.Matcher) int {
+		// the alertname is the most descriptive label, so it comes first
+		if (a.GetName() == "alertname") != (b.GetName() == "alertname") {
+			if a.GetName() == "alertname" {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.GetName(), b.GetName()), cmp.Compare(a.GetValue(), b.GetValue()))
+	})
+
+	prefix := "silence"
+	if len(matchers) > 0 {
+		if name := sanitizeName(matchers[0].GetName()+"-"+matchers[0].GetValue(), 48); name != "" {
+			prefix = name
+		}
+	}
+
+	h := fnv.New64a()
+	for _, m := range matchers {
+		fmt.Fprintf(h, "%s\x00%s\x00%t\x00%t\x00", m.GetName(), m.GetValue(), m.GetIsRegex(), matcherIsEqual(m))
+	}
+	h.Write([]byte(s.GetComment()))
+	hash := fmt.Sprintf("%016x", h.Sum64())
+
+	return []string{prefix + "-" + hash[:4], prefix + "-" + hash[:8], prefix + "-" + hash, s.GetId()}
+}
+
+// silenceNeedsUpdate returns true when the desired silence differs from the silence which currently exists in Alertmanager.
+func silenceNeedsUpdate(desired alertmanagerapi.Silence, current alertmanagerapi.GettableSilence) bool {
+	if desired.Comment != current.Comment || desired.CreatedBy != current.CreatedBy {
+		return true
+	}
+	if !equalMatchers(desired.Matchers, current.Matchers) {
+		return true
+	}
+	// timestamps are stored with second precision in Kubernetes
+	if !desired.EndsAt.Truncate(time.Second).Equal(current.EndsAt.Truncate(time.Second)) {
+		return true
+	}
+	// Alertmanager sets the start time of silences that start in the past to the current time,
+	// so only future start times can be compared
+	now := time.Now()
+	if (desired.StartsAt.After(now) || current.StartsAt.After(now)) &&
+		!desired.StartsAt.Truncate(time.Second).Equal(current.StartsAt.Truncate(time.Second)) {
+		return true
+	}
+	return false
+}
+
+// equalMatchers compares two sets of matchers, ignoring their order.
+func equalMatchers(a, b []alertmanagerapi.Matcher) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	matcherKey := func(m alertmanagerapi.Matcher) string {
+		return fmt.Sprintf("%s\x00%s\x00%t\x00%t", m.GetName(), m.GetValue(), m.GetIsRegex(), matcherIsEqual(m))
+	}
+	keys := map[string]int{}
+	for _, m := range a {
+		keys[matcherKey(m)]++
+	}
+	for _, m := range b {
+		keys[matcherKey(m)]--
+	}
+	for _, count := range keys {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func convertSilenceToPost(in alertmanagerapi.Silence) alertmanagerapi.PostableSilence {
 	return alertmanagerapi.PostableSilence{
 		Matchers:  in.Matchers,
@@ -187,19 +1028,117 @@ func convertSilenceToPost(in alertmanagerapi.Silence) alertmanagerapi.PostableSi
 func generateAlertmanagerSilence(silence alertmanagerprometheusiov1alpha1.Silence) alertmanagerapi.Silence {
 	s := alertmanagerapi.NewSilenceWithDefaults()
 	s.Comment = silence.Spec.Comment
+	s.CreatedBy = silence.Spec.CreatedBy
 	for k, v := range silence.Spec.MatchLabels {
-		m := alertmanagerapi.NewMatcher(k, v, false) // TODO: implement regex support
+		m := alertmanagerapi.NewMatcher(k, v, false)
 		s.Matchers = append(s.Matchers, *m)
 	}
-	s.EndsAt = silence.Spec.EndsAt.Time
-	s.StartsAt = silence.Spec.StartsAt.Time
+	for _, m := range silence.Spec.Matchers {
+		s.Matchers = append(s.Matchers, convertMatcher(m))
+	}
+	s.StartsAt, s.EndsAt = silenceWindow(silence)
 
 	return *s
 }
 
+// silenceWindow resolves the absolute start and end time of the silence.
+// The start time defaults to the creation time of the object, the end time may be given relative to the start time.
+func silenceWindow(silence alertmanagerprometheusiov1alpha1.Silence) (startsAt, endsAt time.Time) {
+	startsAt = silence.Spec.StartsAt.Time
+	if startsAt.IsZero() {
+		startsAt = silence.CreationTimestamp.Time
+	}
+	endsAt = silence.Spec.EndsAt.Time
+	if silence.Spec.Duration != nil {
+		endsAt = startsAt.Add(silence.Spec.Duration.Duration)
+	}
+	return startsAt, endsAt
+}
+
+// validateSilenceSpec checks that the silence can be converted into a valid Alertmanager silence.
+func validateSilenceSpec(spec alertmanagerprometheusiov1alpha1.SilenceSpec) error {
+	if err := validateMatchers(spec); err != nil {
+		return err
+	}
+	return validateSilenceWindow(spec)
+}
+
+// validateSilenceWindow checks that the end of the silence is given either as a timestamp or as a duration.
+func validateSilenceWindow(spec alertmanagerprometheusiov1alpha1.SilenceSpec) error {
+	switch {
+	case spec.Duration != nil && !spec.EndsAt.IsZero():
+		return fmt.Errorf("Only one of endsAt and duration may be set")
+	case spec.Duration == nil && spec.EndsAt.IsZero():
+		return fmt.Errorf("One of endsAt and duration must be set")
+	case spec.Duration != nil && spec.Duration.Duration <= 0:
+		return fmt.Errorf("Invalid duration '%s', it must be positive", spec.Duration.Duration)
+	}
+	return nil
+}
+
+// validateMatchers checks that all matchers of the silence have a valid match type and regular expression.
+func validateMatchers(spec alertmanagerprometheusiov1alpha1.SilenceSpec) error {
+	for _, m := range spec.Matchers {
+		switch m.MatchType {
+		case alertmanagerprometheusiov1alpha1.MatchEqual, alertmanagerprometheusiov1alpha1.MatchNotEqual, "":
+		case alertmanagerprometheusiov1alpha1.MatchRegexp, alertmanagerprometheusiov1alpha1.MatchNotRegexp:
+			// Alertmanager anchors regular expressions at both ends
+			if _, err := regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+				return fmt.Errorf("Invalid regular expression for label '%s': %w", m.Name, err)
+			}
+		default:
+			return fmt.Errorf("Invalid match type '%s' for label '%s'", m.MatchType, m.Name)
+		}
+	}
+	return nil
+}
+
+// convertMatcher converts a matcher of a Silence object into its Alertmanager representation.
+func convertMatcher(in alertmanagerprometheusiov1alpha1.Matcher) alertmanagerapi.Matcher {
+	isRegex := in.MatchType == alertmanagerprometheusiov1alpha1.MatchRegexp ||
+		in.MatchType == alertmanagerprometheusiov1alpha1.MatchNotRegexp
+	isEqual := in.MatchType != alertmanagerprometheusiov1alpha1.MatchNotEqual &&
+		in.MatchType != alertmanagerprometheusiov1alpha1.MatchNotRegexp
+
+	m := alertmanagerapi.NewMatcher(in.Name, in.Value, isRegex)
+	m.SetIsEqual(isEqual)
+	return *m
+}
+
+// convertAlertmanagerMatcher converts a matcher returned by Alertmanager into its Silence object representation.
+func convertAlertmanagerMatcher(in alertmanagerapi.Matcher) alertmanagerprometheusiov1alpha1.Matcher {
+	matchType := alertmanagerprometheusiov1alpha1.MatchEqual
+	switch {
+	case in.GetIsRegex() && matcherIsEqual(in):
+		matchType = alertmanagerprometheusiov1alpha1.MatchRegexp
+	case in.GetIsRegex():
+		matchType = alertmanagerprometheusiov1alpha1.MatchNotRegexp
+	case !matcherIsEqual(in):
+		matchType = alertmanagerprometheusiov1alpha1.MatchNotEqual
+	}
+
+	return alertmanagerprometheusiov1alpha1.Matcher{
+		Name:      in.GetName(),
+		Value:     in.GetValue(),
+		MatchType: matchType,
+	}
+}
+
+// matcherIsEqual returns whether the matcher is positive. Older versions of Alertmanager do not support
+// negative matchers and omit the field, in which case it defaults to true.
+func matcherIsEqual(m alertmanagerapi.Matcher) bool {
+	if isEqual, ok := m.GetIsEqualOk(); ok {
+		return *isEqual
+	}
+	return true
+}
+
 // Sets a label on the resource without removing existing labels
 func setLabel(obj metav1.Object, label string, value string) {
 	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
 	labels[label] = value
 	obj.SetLabels(labels)
 }
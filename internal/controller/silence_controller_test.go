@@ -18,18 +18,182 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	alertmanagerprometheusiov1alpha1 "github.com/jacksgt/alert-operator/api/v1alpha1"
+	"github.com/jacksgt/alert-operator/internal/alertmanagerapi"
 )
 
+// fakeAlertmanager implements the parts of the Alertmanager silence and alert API used by the SilenceReconciler.
+type fakeAlertmanager struct {
+	*httptest.Server
+	mu       sync.Mutex
+	silences map[string]alertmanagerapi.GettableSilence
+	alerts   []alertmanagerapi.GettableAlert
+	nextID   int
+}
+
+func newFakeAlertmanager() *fakeAlertmanager {
+	am := &fakeAlertmanager{silences: map[string]alertmanagerapi.GettableSilence{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v2/silences", func(w http.ResponseWriter, req *http.Request) {
+		am.mu.Lock()
+		defer am.mu.Unlock()
+		silences := []alertmanagerapi.GettableSilence{}
+		for _, s := range am.silences {
+			silences = append(silences, s)
+		}
+		_ = json.NewEncoder(w).Encode(silences)
+	})
+	mux.HandleFunc("POST /api/v2/silences", func(w http.ResponseWriter, req *http.Request) {
+		am.mu.Lock()
+		defer am.mu.Unlock()
+		in := alertmanagerapi.PostableSilence{}
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id := in.GetId()
+		if id == "" {
+			am.nextID++
+			id = fmt.Sprintf("silence-%d", am.nextID)
+		}
+		am.silences[id] = *alertmanagerapi.NewGettableSilence(in.Matchers, in.StartsAt, in.EndsAt, in.CreatedBy,
+			in.Comment, id, *alertmanagerapi.NewSilenceStatus("active"), time.Now())
+		_ = json.NewEncoder(w).Encode(map[string]string{"silenceID": id})
+	})
+	mux.HandleFunc("GET /api/v2/silence/{id}", func(w http.ResponseWriter, req *http.Request) {
+		am.mu.Lock()
+		defer am.mu.Unlock()
+		s, ok := am.silences[req.PathValue("id")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s)
+	})
+	mux.HandleFunc("GET /api/v2/alerts", func(w http.ResponseWriter, req *http.Request) {
+		am.mu.Lock()
+		defer am.mu.Unlock()
+		alerts := append([]alertmanagerapi.GettableAlert{}, am.alerts...)
+		_ = json.NewEncoder(w).Encode(alerts)
+	})
+	mux.HandleFunc("DELETE /api/v2/silence/{id}", func(w http.ResponseWriter, req *http.Request) {
+		am.mu.Lock()
+		defer am.mu.Unlock()
+		s, ok := am.silences[req.PathValue("id")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		s.Status.SetState("expired")
+		s.EndsAt = time.Now()
+		am.silences[s.Id] = s
+	})
+	am.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, req)
+	}))
+	return am
+}
+
+// Client returns an Alertmanager API client which talks to the fake Alertmanager.
+func (am *fakeAlertmanager) Client() *alertmanagerapi.APIClient {
+	cfg := alertmanagerapi.NewConfiguration()
+	cfg.Servers[0].URL = am.URL + "/api/v2"
+	return alertmanagerapi.NewAPIClient(cfg)
+}
+
+// AddSilence creates an active silence directly in the fake Alertmanager, as if it was created in its UI.
+func (am *fakeAlertmanager) AddSilence(comment string) string {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.nextID++
+	id := fmt.Sprintf("silence-%d", am.nextID)
+	matchers := []alertmanagerapi.Matcher{*alertmanagerapi.NewMatcher("alertname", "Watchdog", false)}
+	am.silences[id] = *alertmanagerapi.NewGettableSilence(matchers, time.Now(), time.Now().Add(time.Hour), "ui-user",
+		comment, id, *alertmanagerapi.NewSilenceStatus("active"), time.Now())
+	return id
+}
+
+// AddAlert adds an alert with the given name to the fake Alertmanager, muted by the given silences (if any).
+func (am *fakeAlertmanager) AddAlert(alertName string, silencedBy ...string) string {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	labels := map[string]string{"alertname": alertName, "instance": fmt.Sprintf("instance-%d", len(am.alerts))}
+	fingerprint := alertFingerprint(labels)
+	state := "active"
+	if len(silencedBy) > 0 {
+		state = "suppressed"
+	}
+	am.alerts = append(am.alerts, *alertmanagerapi.NewGettableAlert(labels, map[string]string{}, []alertmanagerapi.Receiver{},
+		fingerprint, time.Now(), time.Now(), time.Now().Add(time.Hour), *alertmanagerapi.NewAlertStatus(state, silencedBy, []string{})))
+	return fingerprint
+}
+
+// Silence returns the silence with the given ID.
+func (am *fakeAlertmanager) Silence(id string) (alertmanagerapi.GettableSilence, bool) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	s, ok := am.silences[id]
+	return s, ok
+}
+
+// conflictingStatusClient fails the given number of status updates with a conflict, as if the object was modified
+// concurrently.
+type conflictingStatusClient struct {
+	client.Client
+	conflicts int
+}
+
+func (c *conflictingStatusClient) Status() client.SubResourceWriter {
+	return &conflictingStatusWriter{SubResourceWriter: c.Client.Status(), client: c}
+}
+
+type conflictingStatusWriter struct {
+	client.SubResourceWriter
+	client *conflictingStatusClient
+}
+
+func (w *conflictingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if w.client.conflicts > 0 {
+		w.client.conflicts--
+		return errors.NewConflict(alertmanagerprometheusiov1alpha1.GroupVersion.WithResource("silences").GroupResource(),
+			obj.GetName(), fmt.Errorf("the object has been modified"))
+	}
+	return w.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+// rejectingClient rejects the creation of objects for which reject returns an error, like an admission webhook.
+type rejectingClient struct {
+	client.Client
+	reject func(obj client.Object) error
+}
+
+func (c *rejectingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.reject(obj); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
 var _ = Describe("Silence Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
@@ -42,7 +206,21 @@ var _ = Describe("Silence Controller", func() {
 		}
 		silence := &alertmanagerprometheusiov1alpha1.Silence{}
 
+		var alertmanager *fakeAlertmanager
+		var recorder *record.FakeRecorder
+		var controllerReconciler *SilenceReconciler
+
 		BeforeEach(func() {
+			alertmanager = newFakeAlertmanager()
+			recorder = record.NewFakeRecorder(100)
+			controllerReconciler = &SilenceReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				Namespace:          "default",
+				AlertmanagerClient: alertmanager.Client(),
+				Recorder:           recorder,
+			}
+
 			By("creating the custom resource for the Kind Silence")
 			err := k8sClient.Get(ctx, typeNamespacedName, silence)
 			if err != nil && errors.IsNotFound(err) {
@@ -51,34 +229,683 @@ var _ = Describe("Silence Controller", func() {
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+						MatchLabels: map[string]string{"alertname": "KubeJobFailed"},
+						EndsAt:      metav1.NewTime(time.Now().Add(time.Hour)),
+						CreatedBy:   "test",
+						Comment:     "testing",
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &alertmanagerprometheusiov1alpha1.Silence{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance Silence")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, resource))).To(BeTrue())
+
+			alertmanager.Close()
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
-			controllerReconciler := &SilenceReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
-			}
-
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("creating the silence in Alertmanager")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, silence)).To(Succeed())
+			Expect(silence.Status.SilenceId).NotTo(BeEmpty())
+			Expect(silence.Labels).To(HaveKeyWithValue(silenceIDLabel, silence.Status.SilenceId))
+			Expect(silence.Finalizers).To(ContainElement(silenceFinalizer))
+			s, ok := alertmanager.Silence(silence.Status.SilenceId)
+			Expect(ok).To(BeTrue())
+			Expect(s.Comment).To(Equal("testing"))
+			Expect(silence.Status.EndsAt).NotTo(BeNil())
+			Expect(silence.Status.EndsAt.Time).To(BeTemporally("~", silence.Spec.EndsAt.Time, time.Second))
+
+			By("reporting the state of the silence")
+			Expect(silence.Status.State).To(Equal(alertmanagerprometheusiov1alpha1.SilenceStateActive))
+			Expect(silence.Status.ObservedGeneration).To(Equal(silence.Generation))
+			Expect(meta.IsStatusConditionTrue(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionSynced)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionExpired)).To(BeTrue())
+
+			By("updating the silence in Alertmanager when the spec changes")
+			silence.Spec.Comment = "still testing"
+			Expect(k8sClient.Update(ctx, silence)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			s, ok = alertmanager.Silence(silence.Status.SilenceId)
+			Expect(ok).To(BeTrue())
+			Expect(s.Comment).To(Equal("still testing"))
+		})
+	})
+
+	Context("When syncing silences from Alertmanager", func() {
+		ctx := context.Background()
+
+		var alertmanager *fakeAlertmanager
+		var recorder *record.FakeRecorder
+		var controllerReconciler *SilenceReconciler
+
+		BeforeEach(func() {
+			alertmanager = newFakeAlertmanager()
+			recorder = record.NewFakeRecorder(100)
+			controllerReconciler = &SilenceReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				Namespace:          "default",
+				AlertmanagerClient: alertmanager.Client(),
+				Recorder:           recorder,
+				ImportPolicy:       SilenceImportPolicyReadOnly,
+			}
+		})
+
+		AfterEach(func() {
+			silences := &alertmanagerprometheusiov1alpha1.SilenceList{}
+			Expect(k8sClient.List(ctx, silences, client.InNamespace("default"))).To(Succeed())
+			for _, silence := range silences.Items {
+				Expect(k8sClient.Delete(ctx, &silence)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&silence)})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			alertmanager.Close()
+		})
+
+		syncAll := func() {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		importedSilences := func(id string) []alertmanagerprometheusiov1alpha1.Silence {
+			silences := &alertmanagerprometheusiov1alpha1.SilenceList{}
+			Expect(k8sClient.List(ctx, silences, client.MatchingLabels{silenceIDLabel: id})).To(Succeed())
+			return silences.Items
+		}
+
+		It("should mirror silences created in Alertmanager as read-only objects", func() {
+			id := alertmanager.AddSilence("created in the UI")
+			syncAll()
+
+			Expect(importedSilences(id)).To(HaveLen(1))
+			silence := &importedSilences(id)[0]
+			key := client.ObjectKeyFromObject(silence)
+			Expect(silence.Labels).To(HaveKeyWithValue(silenceOwnerLabel, silenceOwnerAlertmanager))
+			Expect(silence.Spec.Comment).To(Equal("created in the UI"))
+
+			By("never writing the object back to Alertmanager")
+			silence.Spec.Comment = "edited in Kubernetes"
+			Expect(k8sClient.Update(ctx, silence)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			s, _ := alertmanager.Silence(id)
+			Expect(s.Comment).To(Equal("created in the UI"))
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Finalizers).To(BeEmpty())
+			Expect(silence.Status.State).To(Equal(alertmanagerprometheusiov1alpha1.SilenceStateActive))
+
+			By("following the silence in Alertmanager")
+			syncAll()
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Spec.Comment).To(Equal("created in the UI"))
+
+			By("removing the object once Alertmanager has forgotten the silence")
+			alertmanager.mu.Lock()
+			delete(alertmanager.silences, id)
+			alertmanager.mu.Unlock()
+			syncAll()
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, silence))).To(BeTrue())
+		})
+
+		It("should give imported silences human-readable names", func() {
+			first := alertmanager.AddSilence("maintenance")
+			second := alertmanager.AddSilence("maintenance")
+			syncAll()
+
+			Expect(importedSilences(first)).To(HaveLen(1))
+			Expect(importedSilences(second)).To(HaveLen(1))
+			firstName := importedSilences(first)[0].Name
+			secondName := importedSilences(second)[0].Name
+			// both silences have the same matchers and comment, whichever is imported first gets the shortest name
+			Expect([]string{firstName, secondName}).To(ContainElement(MatchRegexp(`^alertname-watchdog-[0-9a-f]{4}$`)))
+			Expect([]string{firstName, secondName}).To(HaveEach(HavePrefix("alertname-watchdog-")))
+			Expect(secondName).NotTo(Equal(firstName))
+
+			By("keeping the names stable")
+			syncAll()
+			Expect(importedSilences(first)[0].Name).To(Equal(firstName))
+			Expect(importedSilences(second)[0].Name).To(Equal(secondName))
+		})
+
+		It("should never overwrite silences managed by Kubernetes", func() {
+			key := types.NamespacedName{Name: "managed-silence", Namespace: "default"}
+			silence := &alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					MatchLabels: map[string]string{"alertname": "KubeJobFailed"},
+					EndsAt:      metav1.NewTime(time.Now().Add(time.Hour)),
+					Comment:     "from git",
+				},
+			}
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Labels).To(HaveKeyWithValue(silenceOwnerLabel, silenceOwnerKubernetes))
+
+			alertmanager.mu.Lock()
+			s := alertmanager.silences[silence.Status.SilenceId]
+			s.Comment = "edited in the UI"
+			alertmanager.silences[s.Id] = s
+			alertmanager.mu.Unlock()
+
+			syncAll()
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Spec.Comment).To(Equal("from git"))
+
+			By("restoring the silence in Alertmanager from the spec")
+			s, _ = alertmanager.Silence(silence.Status.SilenceId)
+			Expect(s.Comment).To(Equal("from git"))
+			Expect(meta.IsStatusConditionTrue(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionDrifted)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonSilenceModified)))
+		})
+
+		It("should recreate silences which Alertmanager has lost", func() {
+			key := types.NamespacedName{Name: "lost-silence", Namespace: "default"}
+			silence := &alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					MatchLabels: map[string]string{"alertname": "KubeJobFailed"},
+					EndsAt:      metav1.NewTime(time.Now().Add(time.Hour)),
+					Comment:     "from git",
+				},
+			}
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			lostId := silence.Status.SilenceId
+
+			// Alertmanager restarts without persistent storage
+			alertmanager.mu.Lock()
+			delete(alertmanager.silences, lostId)
+			alertmanager.mu.Unlock()
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Status.SilenceId).NotTo(Equal(lostId))
+			s, ok := alertmanager.Silence(silence.Status.SilenceId)
+			Expect(ok).To(BeTrue())
+			Expect(s.Comment).To(Equal("from git"))
+			Expect(meta.FindStatusCondition(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionDrifted).Reason).
+				To(Equal(reasonSilenceMissing))
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonSilenceMissing)))
+
+			By("recreating it without an event recorder")
+			withoutRecorder := *controllerReconciler
+			withoutRecorder.Recorder = nil
+			alertmanager.mu.Lock()
+			delete(alertmanager.silences, silence.Status.SilenceId)
+			alertmanager.mu.Unlock()
+			_, err = withoutRecorder.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			_, ok = alertmanager.Silence(silence.Status.SilenceId)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should report the alerts muted by a silence", func() {
+			controllerReconciler.UnusedThreshold = time.Hour
+			id := alertmanager.AddSilence("created in the UI")
+			syncAll()
+			Expect(importedSilences(id)).To(HaveLen(1))
+			key := client.ObjectKeyFromObject(&importedSilences(id)[0])
+
+			By("marking the silence as unused while it mutes no alerts")
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Status.SilencedAlerts).To(BeZero())
+			Expect(silence.Status.UnusedSince).NotTo(BeNil())
+			unused := meta.FindStatusCondition(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionUnused)
+			Expect(unused).NotTo(BeNil())
+			Expect(unused.Status).To(Equal(metav1.ConditionFalse))
+			Expect(unused.Reason).To(Equal(reasonNoAlertsSilenced))
+
+			By("warning once the threshold has passed")
+			silence.Status.UnusedSince = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+			Expect(k8sClient.Status().Update(ctx, silence)).To(Succeed())
+			syncAll()
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionUnused)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonNoAlertsSilenced)))
+
+			By("listing the muted alerts and their Alert objects")
+			fingerprint := alertmanager.AddAlert("Watchdog", id)
+			alertmanager.AddAlert("Watchdog")
+			alertObj := &alertmanagerprometheusiov1alpha1.Alert{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "watchdog-" + fingerprint,
+					Namespace: "default",
+					Labels:    map[string]string{fingerprintLabel: fingerprint},
+				},
+			}
+			Expect(k8sClient.Create(ctx, alertObj)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, alertObj)).To(Succeed())
+			})
+			syncAll()
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Status.SilencedAlerts).To(BeEquivalentTo(1))
+			Expect(silence.Status.Alerts).To(Equal([]alertmanagerprometheusiov1alpha1.SilencedAlert{{
+				Fingerprint: fingerprint,
+				AlertName:   "Watchdog",
+				Name:        alertObj.Name,
+				Namespace:   "default",
+			}}))
+			Expect(silence.Status.UnusedSince).To(BeNil())
+			unused = meta.FindStatusCondition(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionUnused)
+			Expect(unused.Status).To(Equal(metav1.ConditionFalse))
+			Expect(unused.Reason).To(Equal(reasonAlertsSilenced))
+		})
+
+		It("should adopt silences created in Alertmanager", func() {
+			controllerReconciler.ImportPolicy = SilenceImportPolicyAdopt
+			id := alertmanager.AddSilence("created in the UI")
+			syncAll()
+
+			Expect(importedSilences(id)).To(HaveLen(1))
+			Expect(importedSilences(id)[0].Labels).To(HaveKeyWithValue(silenceOwnerLabel, silenceOwnerKubernetes))
+		})
+
+		It("should not import the silences of deleted Silence objects", func() {
+			key := types.NamespacedName{Name: "deleted-silence", Namespace: "default"}
+			silence := &alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					MatchLabels: map[string]string{"alertname": "KubeJobFailed"},
+					EndsAt:      metav1.NewTime(time.Now().Add(time.Hour)),
+				},
+			}
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			id := silence.Status.SilenceId
+
+			Expect(k8sClient.Delete(ctx, silence)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, silence))).To(BeTrue())
+			s, _ := alertmanager.Silence(id)
+			Expect(s.Status.GetState()).To(Equal(alertmanagerprometheusiov1alpha1.SilenceStateExpired))
+
+			syncAll()
+			Expect(importedSilences(id)).To(BeEmpty())
+		})
+
+		It("should mirror silences which cannot be adopted", func() {
+			controllerReconciler.ImportPolicy = SilenceImportPolicyAdopt
+			controllerReconciler.Client = &rejectingClient{Client: k8sClient, reject: func(obj client.Object) error {
+				if obj.GetLabels()[silenceOwnerLabel] != silenceOwnerKubernetes {
+					return nil
+				}
+				return errors.NewInvalid(alertmanagerprometheusiov1alpha1.GroupVersion.WithKind("Silence").GroupKind(), obj.GetName(),
+					field.ErrorList{field.Invalid(field.NewPath("spec"), "720h", "the silence must not last longer than 168h")})
+			}}
+			id := alertmanager.AddSilence("created in the UI")
+			syncAll()
+
+			Expect(importedSilences(id)).To(HaveLen(1))
+			Expect(importedSilences(id)[0].Labels).To(HaveKeyWithValue(silenceOwnerLabel, silenceOwnerAlertmanager))
+		})
+
+		It("should import the other silences when one of them fails", func() {
+			controllerReconciler.Client = &rejectingClient{Client: k8sClient, reject: func(obj client.Object) error {
+				if obj.(*alertmanagerprometheusiov1alpha1.Silence).Spec.Comment == "broken" {
+					return fmt.Errorf("admission webhook is unavailable")
+				}
+				return nil
+			}}
+			broken := alertmanager.AddSilence("broken")
+			id := alertmanager.AddSilence("created in the UI")
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("admission webhook is unavailable")))
+			Expect(importedSilences(broken)).To(BeEmpty())
+			Expect(importedSilences(id)).To(HaveLen(1))
+		})
+
+		It("should expire unmanaged silences", func() {
+			controllerReconciler.ImportPolicy = SilenceImportPolicyExpireUnmanaged
+			id := alertmanager.AddSilence("created in the UI")
+			syncAll()
+
+			s, _ := alertmanager.Silence(id)
+			Expect(s.Status.GetState()).To(Equal(alertmanagerprometheusiov1alpha1.SilenceStateExpired))
+			Expect(importedSilences(id)).To(BeEmpty())
+		})
+	})
+
+	Context("When reconciling a Silence in a tenant namespace", func() {
+		ctx := context.Background()
+
+		var alertmanager *fakeAlertmanager
+		var controllerReconciler *SilenceReconciler
+
+		BeforeEach(func() {
+			alertmanager = newFakeAlertmanager()
+			controllerReconciler = &SilenceReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				Namespace:          "alert-operator",
+				AlertmanagerClient: alertmanager.Client(),
+				Recorder:           record.NewFakeRecorder(100),
+				NamespaceLabel:     "namespace",
+			}
+		})
+
+		AfterEach(func() {
+			silences := &alertmanagerprometheusiov1alpha1.SilenceList{}
+			Expect(k8sClient.List(ctx, silences, client.InNamespace("default"))).To(Succeed())
+			for _, silence := range silences.Items {
+				silence.Annotations = map[string]string{forceDeleteAnnotation: "true"}
+				Expect(k8sClient.Update(ctx, &silence)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, &silence)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&silence)})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			alertmanager.Close()
+		})
+
+		createSilence := func(name string, labels map[string]string) *alertmanagerprometheusiov1alpha1.Silence {
+			key := types.NamespacedName{Name: name, Namespace: "default"}
+			silence := &alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: labels},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					Matchers: []alertmanagerprometheusiov1alpha1.Matcher{
+						{Name: "alertname", Value: ".+", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp},
+					},
+					EndsAt:  metav1.NewTime(time.Now().Add(time.Hour)),
+					Comment: "maintenance of team-a",
+				},
+			}
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			return silence
+		}
+
+		It("should restrict the silence to alerts from its namespace", func() {
+			silence := createSilence("tenant-silence", nil)
+
+			s, ok := alertmanager.Silence(silence.Status.SilenceId)
+			Expect(ok).To(BeTrue())
+			Expect(s.Matchers).To(ContainElement(*alertmanagerapi.NewMatcher("namespace", "default", false)))
+			Expect(s.Matchers).To(HaveLen(2))
+
+			By("not reporting the enforced matcher as drift")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(silence)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(silence), silence)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(silence.Status.Conditions, alertmanagerprometheusiov1alpha1.SilenceConditionDrifted)).To(BeTrue())
+		})
+
+		It("should not restrict silences when no namespace label is configured", func() {
+			controllerReconciler.NamespaceLabel = ""
+			silence := createSilence("unrestricted-silence", nil)
+
+			s, _ := alertmanager.Silence(silence.Status.SilenceId)
+			Expect(s.Matchers).To(HaveLen(1))
+		})
+
+		It("should not trust the silenceID label", func() {
+			id := alertmanager.AddSilence("created by the platform team")
+			silence := createSilence("hijacking-silence", map[string]string{
+				silenceIDLabel:    id,
+				silenceOwnerLabel: silenceOwnerAlertmanager,
+			})
+
+			Expect(silence.Labels).To(HaveKeyWithValue(silenceOwnerLabel, silenceOwnerKubernetes))
+			Expect(silence.Status.SilenceId).NotTo(Equal(id))
+			s, _ := alertmanager.Silence(id)
+			Expect(s.Comment).To(Equal("created by the platform team"))
+		})
+
+		It("should not post the silence again when its status could not be written", func() {
+			controllerReconciler.Client = &conflictingStatusClient{Client: k8sClient, conflicts: 1}
+			silence := createSilence("conflicting-silence", nil)
+
+			Expect(silence.Status.SilenceId).NotTo(BeEmpty())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(silence)})
+			Expect(err).NotTo(HaveOccurred())
+			alertmanager.mu.Lock()
+			defer alertmanager.mu.Unlock()
+			Expect(alertmanager.silences).To(HaveLen(1))
+		})
+
+		It("should restrict silences labelled as mirrored from Alertmanager to their namespace", func() {
+			silence := createSilence("mirrored-silence", map[string]string{silenceOwnerLabel: silenceOwnerAlertmanager})
+
+			Expect(silence.Labels).To(HaveKeyWithValue(silenceOwnerLabel, silenceOwnerKubernetes))
+			Expect(silence.Finalizers).To(ContainElement(silenceFinalizer))
+			s, ok := alertmanager.Silence(silence.Status.SilenceId)
+			Expect(ok).To(BeTrue())
+			Expect(s.Matchers).To(ContainElement(*alertmanagerapi.NewMatcher("namespace", "default", false)))
+		})
+	})
+
+	Context("When deleting a resource", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "deleted-silence", Namespace: "default"}
+
+		var alertmanager *fakeAlertmanager
+		var recorder *record.FakeRecorder
+		var controllerReconciler *SilenceReconciler
+
+		BeforeEach(func() {
+			alertmanager = newFakeAlertmanager()
+			recorder = record.NewFakeRecorder(100)
+			controllerReconciler = &SilenceReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				Namespace:          "default",
+				AlertmanagerClient: alertmanager.Client(),
+				Recorder:           recorder,
+			}
+
+			silence := &alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					MatchLabels: map[string]string{"alertname": "KubeJobFailed"},
+					EndsAt:      metav1.NewTime(time.Now().Add(time.Hour)),
+				},
+			}
+			Expect(k8sClient.Create(ctx, silence)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			if err := k8sClient.Get(ctx, key, silence); err == nil {
+				silence.Annotations = map[string]string{forceDeleteAnnotation: "true"}
+				Expect(k8sClient.Update(ctx, silence)).To(Succeed())
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			alertmanager.Close()
+		})
+
+		deleteSilence := func() (reconcile.Result, error) {
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			if silence.GetDeletionTimestamp() == nil {
+				Expect(k8sClient.Delete(ctx, silence)).To(Succeed())
+			}
+			return controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		}
+
+		isGone := func() bool {
+			return errors.IsNotFound(k8sClient.Get(ctx, key, &alertmanagerprometheusiov1alpha1.Silence{}))
+		}
+
+		It("should expire the silence in Alertmanager", func() {
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			_, err := deleteSilence()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isGone()).To(BeTrue())
+			s, _ := alertmanager.Silence(silence.Status.SilenceId)
+			Expect(s.Status.GetState()).To(Equal(alertmanagerprometheusiov1alpha1.SilenceStateExpired))
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonDeleted)))
+		})
+
+		It("should remove the finalizer when the silence no longer exists in Alertmanager", func() {
+			alertmanager.mu.Lock()
+			clear(alertmanager.silences)
+			alertmanager.mu.Unlock()
+
+			_, err := deleteSilence()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isGone()).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring("no longer exists")))
+		})
+
+		It("should expire the silence without an event recorder", func() {
+			controllerReconciler.Recorder = nil
+			_, err := deleteSilence()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isGone()).To(BeTrue())
+		})
+
+		It("should retry with backoff", func() {
+			alertmanager.Close()
+
+			result, err := deleteSilence()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", deletionBackoff, time.Second))
+			Expect(isGone()).To(BeFalse())
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonDeletionFailed)))
+
+			By("waiting for the backoff to pass before trying again")
+			result, err = deleteSilence()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(recorder.Events).NotTo(Receive())
+
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			Expect(silence.Annotations).To(HaveKeyWithValue(deletionAttemptsAnnotation, "1"))
+		})
+
+		It("should give up after the last attempt", func() {
+			controllerReconciler.MaxDeletionAttempts = 1
+			alertmanager.Close()
+
+			_, err := deleteSilence()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isGone()).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Giving up")))
+		})
+
+		It("should remove the finalizer when forced", func() {
+			alertmanager.Close()
+			silence := &alertmanagerprometheusiov1alpha1.Silence{}
+			Expect(k8sClient.Get(ctx, key, silence)).To(Succeed())
+			silence.Annotations = map[string]string{forceDeleteAnnotation: "true"}
+			Expect(k8sClient.Update(ctx, silence)).To(Succeed())
+
+			_, err := deleteSilence()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isGone()).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonForceDeleted)))
+		})
+	})
+
+	Context("When converting matchers", func() {
+		It("should round-trip all match types", func() {
+			for _, matchType := range []alertmanagerprometheusiov1alpha1.MatchType{
+				alertmanagerprometheusiov1alpha1.MatchEqual,
+				alertmanagerprometheusiov1alpha1.MatchNotEqual,
+				alertmanagerprometheusiov1alpha1.MatchRegexp,
+				alertmanagerprometheusiov1alpha1.MatchNotRegexp,
+			} {
+				m := alertmanagerprometheusiov1alpha1.Matcher{Name: "severity", Value: "warning|info", MatchType: matchType}
+				Expect(convertAlertmanagerMatcher(convertMatcher(m))).To(Equal(m))
+			}
+		})
+
+		It("should treat a missing match type as equality", func() {
+			m := convertMatcher(alertmanagerprometheusiov1alpha1.Matcher{Name: "severity", Value: "warning"})
+			Expect(m.GetIsRegex()).To(BeFalse())
+			Expect(m.GetIsEqual()).To(BeTrue())
+		})
+
+		It("should reject invalid regular expressions", func() {
+			spec := alertmanagerprometheusiov1alpha1.SilenceSpec{
+				Matchers: []alertmanagerprometheusiov1alpha1.Matcher{
+					{Name: "job", Value: "node-(exporter", MatchType: alertmanagerprometheusiov1alpha1.MatchRegexp},
+				},
+			}
+			Expect(validateMatchers(spec)).NotTo(Succeed())
+			spec.Matchers[0].Value = "node-(exporter|agent)"
+			Expect(validateMatchers(spec)).To(Succeed())
+		})
+	})
+
+	Context("When resolving the time window", func() {
+		created := time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC)
+
+		It("should default the start time to the creation time", func() {
+			silence := alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					Duration: &metav1.Duration{Duration: 4 * time.Hour},
+				},
+			}
+			Expect(validateSilenceWindow(silence.Spec)).To(Succeed())
+			startsAt, endsAt := silenceWindow(silence)
+			Expect(startsAt).To(Equal(created))
+			Expect(endsAt).To(Equal(created.Add(4 * time.Hour)))
+		})
+
+		It("should apply the duration to an explicit start time", func() {
+			silence := alertmanagerprometheusiov1alpha1.Silence{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec: alertmanagerprometheusiov1alpha1.SilenceSpec{
+					StartsAt: metav1.NewTime(created.Add(24 * time.Hour)),
+					Duration: &metav1.Duration{Duration: 30 * time.Minute},
+				},
+			}
+			startsAt, endsAt := silenceWindow(silence)
+			Expect(startsAt).To(Equal(created.Add(24 * time.Hour)))
+			Expect(endsAt).To(Equal(created.Add(24*time.Hour + 30*time.Minute)))
+		})
+
+		It("should require exactly one of endsAt and duration", func() {
+			spec := alertmanagerprometheusiov1alpha1.SilenceSpec{}
+			Expect(validateSilenceWindow(spec)).NotTo(Succeed())
+			spec.EndsAt = metav1.NewTime(created)
+			Expect(validateSilenceWindow(spec)).To(Succeed())
+			spec.Duration = &metav1.Duration{Duration: time.Hour}
+			Expect(validateSilenceWindow(spec)).NotTo(Succeed())
+			spec.EndsAt = metav1.Time{}
+			spec.Duration.Duration = -time.Hour
+			Expect(validateSilenceWindow(spec)).NotTo(Succeed())
 		})
 	})
 })